@@ -2,17 +2,24 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io/fs"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/docs"
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/external"
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/helpers"
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/logging"
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/packages"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/scheduler"
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/server"
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/updater"
-	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func main() {
@@ -34,8 +41,22 @@ func main() {
 	// Parse command line flags
 	docsPath := flag.String("docs-path", defaultDocsPath, "Path to documentation directory (default: OS-specific cache dir)")
 	packagesPath := flag.String("packages-path", "./configs/packages.json", "Path to packages catalog")
+	servicesPath := flag.String("services-config", "./configs/services.json", "Path to the external services registry (JSON or YAML)")
 	defaultVersion := flag.String("version", "12.x", "Default Laravel version")
 	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	transport := flag.String("transport", server.TransportStdio, "Transport to serve on (stdio, sse, http)")
+	addr := flag.String("addr", "127.0.0.1:8080", "Listen address for the sse and http transports")
+	docsSource := flag.String("docs-source", "github", "Where to sync docs from: github, a GitHub Enterprise or mirror URL, or a local directory")
+	docsRepo := flag.String("docs-repo", "laravel/docs", "Repository holding the docs on GitHub or the docs source URL")
+	bootstrap := flag.Bool("bootstrap", true, "Download the default version in the background when it is missing")
+	refreshInterval := flag.Duration("refresh-interval", 6*time.Hour, "How often to refresh downloaded docs and expired external caches (0 disables)")
+	crawlDepth := flag.Int("crawl-depth", external.DefaultCrawlRules.MaxDepth, "How many links deep external service docs are crawled")
+	crawlPages := flag.Int("crawl-pages", external.DefaultCrawlRules.MaxPages, "Maximum pages crawled per external service")
+	crawlDelay := flag.Duration("crawl-delay", external.DefaultCrawlRules.Delay, "Minimum delay between requests to one external docs host")
+	githubToken := flag.String("github-token", "", "GitHub token for docs updates (default: $GITHUB_TOKEN)")
+	importFrom := flag.String("import-from", "", "Import docs from a local laravel/docs checkout or .tar.gz/.zip archive, then exit")
+	importVersions := flag.String("import-versions", "", "Comma-separated versions to import, each optionally as version=ref (default: --version)")
+	importForce := flag.Bool("import-force", false, "Re-import files even if they are unchanged")
 	flag.Parse()
 
 	// Configure logging
@@ -52,6 +73,10 @@ func main() {
 
 	logging.Info("Starting Laravel MCP Companion...")
 
+	// Stop gracefully on Ctrl+C and SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Ensure docs directory exists
 	if err := helpers.EnsureDirExists(*docsPath); err != nil {
 		logging.Error("Failed to create docs directory: %v", err)
@@ -64,10 +89,38 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Initialize updater with the configured docs source
+	if *githubToken == "" {
+		*githubToken = os.Getenv("GITHUB_TOKEN")
+	}
+	source, err := updater.ParseDocSource(*docsSource, *docsRepo, *githubToken)
+	if err != nil {
+		logging.Error("Invalid docs source: %v", err)
+		os.Exit(1)
+	}
+	upd := updater.NewGitHubUpdater(*docsPath)
+	upd.SetSource(source)
+
+	// Import docs offline and exit without starting the server
+	if *importFrom != "" {
+		if err := importDocs(ctx, upd, *importFrom, *importVersions, *defaultVersion, *importForce); err != nil {
+			logging.Error("Import failed: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize documentation manager
 	docManager := docs.NewManager(*docsPath, *defaultVersion)
 	logging.Info("Initialized documentation manager (path: %s, default: %s)", *docsPath, *defaultVersion)
 
+	// Build search indexes in the background; searches build missing ones on demand
+	go func() {
+		start := time.Now()
+		indexed := docManager.BuildIndexes(ctx)
+		logging.Info("Built search indexes for %d versions in %s", len(indexed), time.Since(start).Round(time.Millisecond))
+	}()
+
 	// Initialize package catalog
 	catalog, err := packages.NewCatalog(*packagesPath)
 	if err != nil {
@@ -76,12 +129,31 @@ func main() {
 	}
 	logging.Info("Initialized package catalog (path: %s)", *packagesPath)
 
-	// Initialize updater and scraper
-	upd := updater.NewGitHubUpdater(*docsPath)
+	// Initialize scraper
 	scraper := external.NewWebScraper()
 
+	// Load the external services registry; without one, the built-in services are used
+	// unless a registry was asked for explicitly
+	services, err := external.LoadServices(*servicesPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !flagSet("services-config"):
+		services = external.DefaultServices
+		logging.Info("No services config at %s, using %d built-in external services", *servicesPath, len(services))
+	case err != nil:
+		logging.Error("Failed to load external services: %v", err)
+		os.Exit(1)
+	default:
+		logging.Info("Loaded %d external services (path: %s)", len(services), *servicesPath)
+	}
+
 	// Initialize external manager with cache path from helper
 	externalManager := external.NewExternalManager(defaultExternalCachePath)
+	externalManager.SetServices(services)
+	externalManager.SetCrawlRules(external.CrawlRules{
+		MaxDepth: *crawlDepth,
+		MaxPages: *crawlPages,
+		Delay:    *crawlDelay,
+	})
 	logging.Info("Initialized updater, web scraper, and external manager")
 
 	// Create server
@@ -95,7 +167,7 @@ func main() {
 		logging.Error("Failed to register doc tools: %v", err)
 		os.Exit(1)
 	}
-	logging.Info("Registered documentation tools (6 tools)")
+	logging.Info("Registered documentation tools (9 tools)")
 
 	// Register package tools
 	srv.RegisterPackageTools(catalog)
@@ -103,18 +175,74 @@ func main() {
 
 	// Register external tools (update & info)
 	srv.RegisterExternalTools(upd, scraper)
-	logging.Info("Registered update and info tools (2 tools)")
+	logging.Info("Registered update and info tools (3 tools)")
 
 	// Register external service tools with the external manager
 	srv.RegisterExternalServiceTools(externalManager)
-	logging.Info("Registered external service tools (4 tools)")
+	logging.Info("Registered external service tools (6 tools)")
 
-	// Start the server (blocking call)
-	logging.Info("Server ready with 16 total tools, starting event loop...")
+	// Register workflow prompts
+	srv.RegisterPrompts(catalog)
+	logging.Info("Registered prompts (3 prompts)")
 
-	// Start the MCP server over stdio using new SDK
-	if err := srv.GetMCPServer().Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+	// Expose documentation files as resources
+	resourceCount := srv.RegisterDocResources(ctx)
+	logging.Info("Registered documentation resources (%d files, 2 templates)", resourceCount)
+
+	// Download the default version on first run without blocking the MCP handshake
+	if *bootstrap && !docManager.HasVersion(*defaultVersion) {
+		srv.BootstrapDocs(ctx, *defaultVersion)
+	}
+
+	// Keep content fresh in the background
+	if *refreshInterval > 0 {
+		sched := scheduler.New(upd, externalManager, *refreshInterval)
+		sched.OnDocsChanged(func(version string) {
+			if err := docManager.RefreshIndex(ctx, version); err != nil {
+				logging.Warn("Failed to rebuild search index for %s: %v", version, err)
+			}
+			srv.RefreshDocResources(ctx, version)
+		})
+		go sched.Run(ctx)
+	}
+
+	// Start the server (blocking call)
+	logging.Info("Server ready with 22 total tools, starting %s transport...", *transport)
+	if err := srv.Serve(ctx, *transport, *addr); err != nil {
 		logging.Error("Server error: %v", err)
 		os.Exit(1)
 	}
+	logging.Info("Server stopped")
+}
+
+// importDocs imports each requested version from a local docs source. specs is a
+// comma-separated list of version[=ref] entries and defaults to defaultVersion.
+func importDocs(ctx context.Context, upd *updater.GitHubUpdater, source, specs, defaultVersion string, force bool) error {
+	if specs == "" {
+		specs = defaultVersion
+	}
+
+	for _, spec := range strings.Split(specs, ",") {
+		version, ref, _ := strings.Cut(strings.TrimSpace(spec), "=")
+		logging.Info("Importing Laravel %s documentation from %s", version, source)
+
+		report, err := upd.ImportLocal(ctx, source, version, ref, force)
+		if err != nil {
+			return fmt.Errorf("%s: %w", version, err)
+		}
+		logging.Info("%s", report.Summary())
+	}
+
+	return nil
+}
+
+// flagSet reports whether the named flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
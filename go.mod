@@ -2,7 +2,11 @@ module github.com/izzamoe/laravel-mcp-companion-go
 
 go 1.24.0
 
-require github.com/modelcontextprotocol/go-sdk v1.1.0
+require (
+	github.com/modelcontextprotocol/go-sdk v1.1.0
+	golang.org/x/net v0.50.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/google/jsonschema-go v0.3.0 // indirect
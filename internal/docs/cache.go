@@ -10,10 +10,17 @@ type cacheEntry struct {
 	timestamp time.Time
 }
 
+// searchEntry holds typed search results, []SearchHit for a single version or
+// []VersionResults across versions
+type searchEntry struct {
+	results   any
+	timestamp time.Time
+}
+
 // Cache manages document caching with TTL
 type Cache struct {
 	fileCache   map[string]cacheEntry
-	searchCache map[string]cacheEntry
+	searchCache map[string]searchEntry
 	mu          sync.RWMutex
 	ttl         time.Duration
 }
@@ -22,7 +29,7 @@ type Cache struct {
 func NewCache() *Cache {
 	return &Cache{
 		fileCache:   make(map[string]cacheEntry),
-		searchCache: make(map[string]cacheEntry),
+		searchCache: make(map[string]searchEntry),
 		ttl:         5 * time.Minute,
 	}
 }
@@ -61,30 +68,31 @@ func (c *Cache) Set(key, content string) {
 	}
 }
 
-// GetSearch retrieves search results from cache
-func (c *Cache) GetSearch(query string) (string, bool) {
+// GetSearch retrieves search results from cache. Callers must not modify
+// the returned results, they are shared with later lookups.
+func (c *Cache) GetSearch(query string) (any, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	entry, exists := c.searchCache[query]
 	if !exists {
-		return "", false
+		return nil, false
 	}
 
 	if time.Since(entry.timestamp) > c.ttl {
-		return "", false
+		return nil, false
 	}
 
-	return entry.content, true
+	return entry.results, true
 }
 
 // SetSearch stores search results in cache
-func (c *Cache) SetSearch(query, results string) {
+func (c *Cache) SetSearch(query string, results any) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.searchCache[query] = cacheEntry{
-		content:   results,
+	c.searchCache[query] = searchEntry{
+		results:   results,
 		timestamp: time.Now(),
 	}
 
@@ -100,7 +108,7 @@ func (c *Cache) Clear() {
 	defer c.mu.Unlock()
 
 	c.fileCache = make(map[string]cacheEntry)
-	c.searchCache = make(map[string]cacheEntry)
+	c.searchCache = make(map[string]searchEntry)
 }
 
 // evictOldest removes oldest 20% of file cache entries
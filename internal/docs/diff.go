@@ -0,0 +1,477 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// diffContextLines is the number of unchanged lines shown around each change
+	diffContextLines = 3
+
+	// minBlockSimilarity is how alike two code blocks must be to diff them as
+	// one changed block rather than a removed and an added one
+	minBlockSimilarity = 0.5
+)
+
+// codeTokenPattern matches the identifiers and literals compared between code blocks
+var codeTokenPattern = regexp.MustCompile(`[\w$]+`)
+
+// SectionChange describes how one section differs between two versions
+type SectionChange struct {
+	Title        string
+	Anchor       string
+	Level        int
+	ProseChanged bool
+	CodeDiffs    []string // unified diffs of changed code blocks
+}
+
+// DocDiff is a section-aware comparison of a file between two versions
+type DocDiff struct {
+	Filename    string
+	FromVersion string
+	ToVersion   string
+	FromMissing bool // the file does not exist in FromVersion
+	ToMissing   bool // the file does not exist in ToVersion
+	Added       []SectionChange
+	Removed     []SectionChange
+	Changed     []SectionChange
+	Unchanged   int
+}
+
+// CompareDoc compares a documentation file between two versions section by section
+func (m *Manager) CompareDoc(ctx context.Context, filename, fromVersion, toVersion string) (*DocDiff, error) {
+	for _, version := range []string{fromVersion, toVersion} {
+		if !m.isKnownVersion(version) {
+			return nil, fmt.Errorf("unsupported version: %s", version)
+		}
+	}
+
+	diff := &DocDiff{
+		Filename:    filename,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+	}
+
+	fromContent, fromErr := m.ReadDoc(ctx, fromVersion, filename)
+	toContent, toErr := m.ReadDoc(ctx, toVersion, filename)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if fromErr != nil && toErr != nil {
+		return nil, fmt.Errorf("%s not available in %s or %s: %w", filename, fromVersion, toVersion, toErr)
+	}
+	diff.FromMissing = fromErr != nil
+	diff.ToMissing = toErr != nil
+
+	fromSections := keyedSections(ParseSections(fromContent))
+	toSections := keyedSections(ParseSections(toContent))
+
+	fromByKey := make(map[string]Section, len(fromSections))
+	for _, ks := range fromSections {
+		fromByKey[ks.key] = ks.section
+	}
+	toKeys := make(map[string]bool, len(toSections))
+
+	for _, ks := range toSections {
+		toKeys[ks.key] = true
+		sec := ks.section
+
+		old, ok := fromByKey[ks.key]
+		if !ok {
+			diff.Added = append(diff.Added, newSectionChange(sec))
+			continue
+		}
+
+		if sectionHash(old) == sectionHash(sec) {
+			diff.Unchanged++
+			continue
+		}
+
+		change := newSectionChange(sec)
+		oldProse, oldCode := splitProseAndCode(old.Body)
+		newProse, newCode := splitProseAndCode(sec.Body)
+
+		change.ProseChanged = normalizeSpace(oldProse) != normalizeSpace(newProse) || old.Title != sec.Title
+
+		change.CodeDiffs = codeBlockDiffs(oldCode, newCode, fromVersion, toVersion)
+
+		diff.Changed = append(diff.Changed, change)
+	}
+
+	for _, ks := range fromSections {
+		if !toKeys[ks.key] {
+			diff.Removed = append(diff.Removed, newSectionChange(ks.section))
+		}
+	}
+
+	return diff, nil
+}
+
+// FormatDocDiff renders a CompareDoc result as Markdown
+func FormatDocDiff(diff *DocDiff) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("# Diff of %s: Laravel %s → %s\n\n", diff.Filename, diff.FromVersion, diff.ToVersion))
+
+	switch {
+	case diff.FromMissing:
+		output.WriteString(fmt.Sprintf("**Note:** %s does not exist in %s; every section is new.\n\n", diff.Filename, diff.FromVersion))
+	case diff.ToMissing:
+		output.WriteString(fmt.Sprintf("**Note:** %s does not exist in %s; every section was removed.\n\n", diff.Filename, diff.ToVersion))
+	}
+
+	output.WriteString(fmt.Sprintf("**Summary:** %d added, %d removed, %d changed, %d unchanged sections\n\n",
+		len(diff.Added), len(diff.Removed), len(diff.Changed), diff.Unchanged))
+
+	if len(diff.Added) > 0 {
+		output.WriteString("## Added Sections\n\n")
+		for _, change := range diff.Added {
+			output.WriteString(fmt.Sprintf("- %s#%s - %s\n", diff.Filename, change.Anchor, change.Title))
+		}
+		output.WriteString("\n")
+	}
+
+	if len(diff.Removed) > 0 {
+		output.WriteString("## Removed Sections\n\n")
+		for _, change := range diff.Removed {
+			output.WriteString(fmt.Sprintf("- %s#%s - %s\n", diff.Filename, change.Anchor, change.Title))
+		}
+		output.WriteString("\n")
+	}
+
+	if len(diff.Changed) > 0 {
+		output.WriteString("## Changed Sections\n\n")
+		for _, change := range diff.Changed {
+			output.WriteString(fmt.Sprintf("### %s (#%s)\n\n", change.Title, change.Anchor))
+			if change.ProseChanged {
+				output.WriteString("- Text changed\n")
+			}
+			if len(change.CodeDiffs) > 0 {
+				output.WriteString(fmt.Sprintf("- %d code blocks changed\n", len(change.CodeDiffs)))
+			}
+			output.WriteString("\n")
+			for _, d := range change.CodeDiffs {
+				output.WriteString("```diff\n")
+				output.WriteString(d)
+				output.WriteString("```\n\n")
+			}
+		}
+	}
+
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+		output.WriteString("No differences found.\n")
+	}
+
+	return output.String()
+}
+
+// keyedSection pairs a section with the key used to match it across versions
+type keyedSection struct {
+	key     string
+	section Section
+}
+
+// keyedSections keys sections by anchor, numbering repeated anchors
+func keyedSections(sections []Section) []keyedSection {
+	seen := make(map[string]int)
+	keyed := make([]keyedSection, 0, len(sections))
+
+	for _, sec := range sections {
+		key := sec.Anchor
+		if sec.Level == 0 {
+			key = "(preamble)"
+		}
+		seen[key]++
+		if seen[key] > 1 {
+			key = fmt.Sprintf("%s~%d", key, seen[key])
+		}
+		keyed = append(keyed, keyedSection{key: key, section: sec})
+	}
+
+	return keyed
+}
+
+// newSectionChange creates a change record for a section
+func newSectionChange(sec Section) SectionChange {
+	title := sec.Title
+	if sec.Level == 0 {
+		title = "(introduction)"
+	}
+	return SectionChange{
+		Title:  title,
+		Anchor: sec.Anchor,
+		Level:  sec.Level,
+	}
+}
+
+// splitProseAndCode separates a section body into prose text and the lines of each code block
+func splitProseAndCode(body string) (string, [][]string) {
+	var prose []string
+	var code [][]string
+
+	for _, b := range splitBlocks(body) {
+		if !b.isCode {
+			prose = append(prose, b.text)
+			continue
+		}
+
+		lines := strings.Split(b.text, "\n")
+		if len(lines) > 0 {
+			lines = lines[1:]
+		}
+		if len(lines) > 0 && isFence(strings.TrimSpace(lines[len(lines)-1])) {
+			lines = lines[:len(lines)-1]
+		}
+		code = append(code, lines)
+	}
+
+	return strings.Join(prose, "\n\n"), code
+}
+
+// normalizeSpace collapses all whitespace runs to single spaces
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// codeBlockDiffs diffs the code blocks of two versions of a section. Identical
+// blocks are matched through their longest common subsequence, so an inserted
+// block does not shift the others. The blocks between two matches are paired
+// by similarity, and any left unpaired are diffed as added or removed.
+func codeBlockDiffs(oldCode, newCode [][]string, fromLabel, toLabel string) []string {
+	var diffs []string
+	var removed, added [][]string
+
+	// diffGap diffs the unmatched blocks between two identical ones
+	diffGap := func() {
+		partner := pairSimilarBlocks(removed, added)
+		paired := make([]bool, len(removed))
+		for j, b := range added {
+			var a []string
+			if k := partner[j]; k >= 0 {
+				a = removed[k]
+				paired[k] = true
+			}
+			if d := unifiedDiff(a, b, fromLabel, toLabel); d != "" {
+				diffs = append(diffs, d)
+			}
+		}
+		for k, a := range removed {
+			if d := unifiedDiff(a, nil, fromLabel, toLabel); !paired[k] && d != "" {
+				diffs = append(diffs, d)
+			}
+		}
+		removed, added = nil, nil
+	}
+
+	i, j := 0, 0
+	for _, op := range lineDiff(blockKeys(oldCode), blockKeys(newCode)) {
+		switch op.kind {
+		case ' ':
+			diffGap()
+			i++
+			j++
+		case '-':
+			removed = append(removed, oldCode[i])
+			i++
+		case '+':
+			added = append(added, newCode[j])
+			j++
+		}
+	}
+	diffGap()
+
+	return diffs
+}
+
+// pairSimilarBlocks pairs each added block with the removed block it most
+// likely replaced, most similar pairs first. The result holds the index of the
+// removed partner of every added block, or -1 when it has none.
+func pairSimilarBlocks(removed, added [][]string) []int {
+	type candidate struct {
+		removed, added int
+		score          float64
+	}
+	var candidates []candidate
+	for k, a := range removed {
+		for j, b := range added {
+			if score := blockSimilarity(a, b); score >= minBlockSimilarity {
+				candidates = append(candidates, candidate{k, j, score})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(x, y int) bool {
+		return candidates[x].score > candidates[y].score
+	})
+
+	partner := make([]int, len(added))
+	for j := range partner {
+		partner[j] = -1
+	}
+	taken := make([]bool, len(removed))
+	for _, c := range candidates {
+		if partner[c.added] < 0 && !taken[c.removed] {
+			partner[c.added] = c.removed
+			taken[c.removed] = true
+		}
+	}
+	return partner
+}
+
+// blockKeys returns the text of each code block, for matching identical blocks
+func blockKeys(code [][]string) []string {
+	keys := make([]string, len(code))
+	for i, lines := range code {
+		keys[i] = strings.Join(lines, "\n")
+	}
+	return keys
+}
+
+// blockSimilarity rates how alike two code blocks are from 0 to 1 by the
+// longest common subsequence of their tokens
+func blockSimilarity(a, b []string) float64 {
+	aTokens := codeTokenPattern.FindAllString(strings.Join(a, "\n"), -1)
+	bTokens := codeTokenPattern.FindAllString(strings.Join(b, "\n"), -1)
+	if len(aTokens)+len(bTokens) == 0 {
+		return 1
+	}
+
+	common := 0
+	for _, op := range lineDiff(aTokens, bTokens) {
+		if op.kind == ' ' {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(aTokens)+len(bTokens))
+}
+
+// diffOp is one line of an edit script
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// lineDiff computes an edit script turning a into b using the longest common subsequence
+func lineDiff(a, b []string) []diffOp {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+
+	return ops
+}
+
+// unifiedDiff renders the differences between a and b as a unified diff,
+// returning an empty string when they are equal
+func unifiedDiff(a, b []string, fromLabel, toLabel string) string {
+	ops := lineDiff(a, b)
+
+	changed := false
+	for _, op := range ops {
+		if op.kind != ' ' {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromLabel, toLabel))
+
+	// Line numbers in a and b before each op
+	aLine := make([]int, len(ops)+1)
+	bLine := make([]int, len(ops)+1)
+	for k, op := range ops {
+		aLine[k+1], bLine[k+1] = aLine[k], bLine[k]
+		if op.kind != '+' {
+			aLine[k+1]++
+		}
+		if op.kind != '-' {
+			bLine[k+1]++
+		}
+	}
+
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+
+		// Grow the hunk until there are more than 2*context unchanged lines in a row
+		start := max(k-diffContextLines, 0)
+		end := k
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContextLines {
+				end = min(end+diffContextLines, len(ops))
+				break
+			}
+			end = run
+		}
+
+		output.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(aLine[start], aLine[end]-aLine[start]), hunkRange(bLine[start], bLine[end]-bLine[start])))
+		for _, op := range ops[start:end] {
+			output.WriteString(fmt.Sprintf("%c%s\n", op.kind, op.line))
+		}
+
+		k = end
+	}
+
+	return output.String()
+}
+
+// hunkRange formats the start and length of a hunk side. An empty side starts
+// at the line before the change, so a block missing on one side reads 0,0.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
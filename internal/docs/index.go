@@ -0,0 +1,323 @@
+package docs
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// BM25 tuning parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// titleWeight is how many times a term in a section heading counts
+	// compared to the same term in the section body
+	titleWeight = 3
+)
+
+// stopWords are ignored when indexing and querying
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "how": true, "in": true,
+	"is": true, "it": true, "of": true, "on": true, "or": true, "that": true,
+	"the": true, "this": true, "to": true, "with": true, "you": true, "your": true,
+}
+
+// SearchHit is a single ranked section matching a query
+type SearchHit struct {
+	Version string
+	File    string
+	Section string
+	Anchor  string
+	Score   float64
+
+	// hash identifies the exact section text so identical passages can be
+	// recognised across versions
+	hash string
+}
+
+// FileHits groups the section hits of a single file
+type FileHits struct {
+	File     string
+	Score    float64
+	Sections []SearchHit
+}
+
+// indexedSection is one searchable unit of a documentation file
+type indexedSection struct {
+	file   string
+	title  string
+	anchor string
+	hash   string
+	length int
+}
+
+// posting records how often a term occurs in a section
+type posting struct {
+	section int
+	freq    int
+}
+
+// versionIndex is an inverted index over the sections of one version
+type versionIndex struct {
+	sections  []indexedSection
+	hashes    map[string]string // file#anchor -> section hash
+	postings  map[string][]posting
+	avgLength float64
+	builtAt   time.Time
+}
+
+// SearchIndex holds a BM25 index for every indexed documentation version
+type SearchIndex struct {
+	versions map[string]*versionIndex
+	mu       sync.RWMutex
+}
+
+// NewSearchIndex creates an empty search index
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{
+		versions: make(map[string]*versionIndex),
+	}
+}
+
+// get returns the index for a version if it has been built
+func (si *SearchIndex) get(version string) (*versionIndex, bool) {
+	si.mu.RLock()
+	defer si.mu.RUnlock()
+
+	idx, ok := si.versions[version]
+	return idx, ok
+}
+
+// set stores the index for a version
+func (si *SearchIndex) set(version string, idx *versionIndex) {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	si.versions[version] = idx
+}
+
+// Remove drops the index for a version so it is rebuilt on next use
+func (si *SearchIndex) Remove(version string) {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	delete(si.versions, version)
+}
+
+// BuiltAt returns when the index for a version was built
+func (si *SearchIndex) BuiltAt(version string) (time.Time, bool) {
+	idx, ok := si.get(version)
+	if !ok {
+		return time.Time{}, false
+	}
+	return idx.builtAt, true
+}
+
+// buildVersionIndex reads every markdown file in versionPath and indexes its sections
+func buildVersionIndex(ctx context.Context, versionPath string) (*versionIndex, error) {
+	entries, err := os.ReadDir(versionPath)
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+
+	idx := &versionIndex{
+		hashes:   make(map[string]string),
+		postings: make(map[string][]posting),
+		builtAt:  time.Now(),
+	}
+
+	totalLength := 0
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(versionPath, entry.Name()))
+		if os.IsNotExist(err) {
+			// The directory was swapped out while it was read
+			return nil, fmt.Errorf("read %s: %w", entry.Name(), err)
+		}
+		if err != nil {
+			continue
+		}
+
+		for _, sec := range ParseSections(string(data)) {
+			freqs := make(map[string]int)
+			length := 0
+
+			for _, term := range tokenize(sec.Title) {
+				freqs[term] += titleWeight
+				length += titleWeight
+			}
+			for _, term := range tokenize(sec.Body) {
+				freqs[term]++
+				length++
+			}
+
+			if length == 0 {
+				continue
+			}
+
+			hash := sectionHash(sec)
+			key := entry.Name() + "#" + sec.Anchor
+			if _, exists := idx.hashes[key]; !exists {
+				idx.hashes[key] = hash
+			}
+
+			id := len(idx.sections)
+			idx.sections = append(idx.sections, indexedSection{
+				file:   entry.Name(),
+				title:  sec.Title,
+				anchor: sec.Anchor,
+				hash:   hash,
+				length: length,
+			})
+			for term, freq := range freqs {
+				idx.postings[term] = append(idx.postings[term], posting{section: id, freq: freq})
+			}
+			totalLength += length
+		}
+	}
+
+	if len(idx.sections) > 0 {
+		idx.avgLength = float64(totalLength) / float64(len(idx.sections))
+	}
+
+	return idx, nil
+}
+
+// search scores every section against the query using BM25
+func (idx *versionIndex) search(query string) []SearchHit {
+	terms := tokenize(query)
+	if len(terms) == 0 || len(idx.sections) == 0 {
+		return nil
+	}
+
+	n := float64(len(idx.sections))
+	scores := make(map[int]float64)
+	seen := make(map[string]bool)
+
+	for _, term := range terms {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+		for _, p := range postings {
+			tf := float64(p.freq)
+			norm := 1 - bm25B + bm25B*float64(idx.sections[p.section].length)/idx.avgLength
+			scores[p.section] += idf * (tf * (bm25K1 + 1)) / (tf + bm25K1*norm)
+		}
+	}
+
+	hits := make([]SearchHit, 0, len(scores))
+	for id, score := range scores {
+		sec := idx.sections[id]
+		hits = append(hits, SearchHit{
+			File:    sec.file,
+			Section: sec.title,
+			Anchor:  sec.anchor,
+			Score:   score,
+			hash:    sec.hash,
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score == hits[j].Score {
+			if hits[i].File == hits[j].File {
+				return hits[i].Anchor < hits[j].Anchor
+			}
+			return hits[i].File < hits[j].File
+		}
+		return hits[i].Score > hits[j].Score
+	})
+
+	return hits
+}
+
+// sectionHash fingerprints a section's heading and whitespace-normalised body
+func sectionHash(sec Section) string {
+	sum := sha1.Sum([]byte(sec.Title + "\n" + strings.Join(strings.Fields(sec.Body), " ")))
+	return hex.EncodeToString(sum[:])
+}
+
+// GroupHitsByFile groups section hits per file, ordered by the best section score
+func GroupHitsByFile(hits []SearchHit) []FileHits {
+	var groups []FileHits
+	positions := make(map[string]int)
+
+	for _, hit := range hits {
+		pos, ok := positions[hit.File]
+		if !ok {
+			pos = len(groups)
+			positions[hit.File] = pos
+			groups = append(groups, FileHits{File: hit.File})
+		}
+		groups[pos].Sections = append(groups[pos].Sections, hit)
+	}
+
+	// A file scores as its best section plus a small bonus for every other matching section
+	for i := range groups {
+		best := groups[i].Sections[0].Score
+		rest := 0.0
+		for _, sec := range groups[i].Sections[1:] {
+			rest += sec.Score
+		}
+		groups[i].Score = best + 0.1*rest
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Score > groups[j].Score
+	})
+
+	return groups
+}
+
+// tokenize lowercases text, splits it into words and drops stop words
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if len(word) < 2 || stopWords[word] {
+			continue
+		}
+		terms = append(terms, stem(word))
+	}
+	return terms
+}
+
+// stem applies a light plural-stripping so "queues" matches "queue"
+func stem(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
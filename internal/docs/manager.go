@@ -1,21 +1,40 @@
 package docs
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/models"
 )
 
+const (
+	// maxSectionsPerFile limits how many matching sections are listed per file
+	maxSectionsPerFile = 5
+
+	// maxContextResults limits how many passages SearchWithContext returns
+	maxContextResults = 20
+
+	// swapWaitAttempts and swapWaitDelay bound how long a reader waits for a
+	// version directory an update is swapping into place
+	swapWaitAttempts = 50
+	swapWaitDelay    = 10 * time.Millisecond
+)
+
 // Manager handles documentation operations
 type Manager struct {
 	DocsPath       string
 	defaultVersion string
 	versions       []string
 	cache          *Cache
+	index          *SearchIndex
 	mu             sync.RWMutex
 }
 
@@ -26,11 +45,56 @@ func NewManager(docsPath, defaultVersion string) *Manager {
 		defaultVersion: defaultVersion,
 		versions:       models.SupportedVersions,
 		cache:          NewCache(),
+		index:          NewSearchIndex(),
+	}
+}
+
+// DefaultVersion returns the version used when none is given
+func (m *Manager) DefaultVersion() string {
+	return m.defaultVersion
+}
+
+// Versions returns the supported versions plus any other version directory on
+// disk, such as one downloaded after a new Laravel release, newest first
+func (m *Manager) Versions() []string {
+	seen := make(map[string]bool)
+	var versions []string
+	for _, version := range m.versions {
+		seen[version] = true
+		versions = append(versions, version)
+	}
+
+	if entries, err := os.ReadDir(m.DocsPath); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() && models.IsVersionName(entry.Name()) && !seen[entry.Name()] {
+				seen[entry.Name()] = true
+				versions = append(versions, entry.Name())
+			}
+		}
 	}
+
+	models.SortVersions(versions)
+	return versions
+}
+
+// HasVersion reports whether a version is present on disk
+func (m *Manager) HasVersion(version string) bool {
+	info, err := os.Stat(filepath.Join(m.DocsPath, version))
+	return err == nil && info.IsDir()
+}
+
+// isKnownVersion reports whether version is supported or present on disk
+func (m *Manager) isKnownVersion(version string) bool {
+	for _, v := range m.Versions() {
+		if v == version {
+			return true
+		}
+	}
+	return false
 }
 
 // ListDocs returns list of available documentation files
-func (m *Manager) ListDocs(version string) ([]string, error) {
+func (m *Manager) ListDocs(ctx context.Context, version string) ([]string, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -38,15 +102,24 @@ func (m *Manager) ListDocs(version string) ([]string, error) {
 		version = m.defaultVersion
 	}
 
-	versionPath := filepath.Join(m.DocsPath, version)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	// Check if version directory exists
-	if _, err := os.Stat(versionPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("version %s not found", version)
+	if !models.IsVersionName(version) {
+		return nil, fmt.Errorf("invalid version: %s", version)
 	}
 
+	versionPath := filepath.Join(m.DocsPath, version)
+
 	// Read directory
 	entries, err := os.ReadDir(versionPath)
+	if os.IsNotExist(err) && m.waitForSwap(version) {
+		entries, err = os.ReadDir(versionPath)
+	}
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("version %s not found", version)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("read directory: %w", err)
 	}
@@ -62,7 +135,7 @@ func (m *Manager) ListDocs(version string) ([]string, error) {
 }
 
 // ReadDoc reads a documentation file
-func (m *Manager) ReadDoc(version, filename string) (string, error) {
+func (m *Manager) ReadDoc(ctx context.Context, version, filename string) (string, error) {
 	if version == "" {
 		version = m.defaultVersion
 	}
@@ -81,8 +154,15 @@ func (m *Manager) ReadDoc(version, filename string) (string, error) {
 		return content, nil
 	}
 
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
 	// Read file
 	data, err := os.ReadFile(fullPath)
+	if os.IsNotExist(err) && m.waitForSwap(version) {
+		data, err = os.ReadFile(fullPath)
+	}
 	if err != nil {
 		if os.IsNotExist(err) {
 			return "", fmt.Errorf("document not found: %s", filename)
@@ -98,232 +178,285 @@ func (m *Manager) ReadDoc(version, filename string) (string, error) {
 	return content, nil
 }
 
-// SearchDocs searches across documentation files
-func (m *Manager) SearchDocs(query, version string) (string, error) {
+// BuildIndex (re)builds the search index for a version
+func (m *Manager) BuildIndex(ctx context.Context, version string) error {
 	if version == "" {
 		version = m.defaultVersion
 	}
 
-	// Check cache
-	cacheKey := fmt.Sprintf("search:%s:%s", version, query)
-	if results, found := m.cache.GetSearch(cacheKey); found {
-		return results, nil
+	// The version comes from tool input and names a directory below DocsPath
+	if !models.IsVersionName(version) {
+		return fmt.Errorf("invalid version: %s", version)
 	}
 
 	versionPath := filepath.Join(m.DocsPath, version)
+	idx, err := buildVersionIndex(ctx, versionPath)
+	if errors.Is(err, fs.ErrNotExist) && m.waitForSwap(version) {
+		idx, err = buildVersionIndex(ctx, versionPath)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("version %s not found", version)
+	}
+	if err != nil {
+		return err
+	}
+
+	m.index.set(version, idx)
+	return nil
+}
 
-	// Check if version exists
-	if _, err := os.Stat(versionPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("version %s not found", version)
+// BuildIndexes builds the search index for every version present on disk
+// and returns the versions that were indexed
+func (m *Manager) BuildIndexes(ctx context.Context) []string {
+	var indexed []string
+	for _, version := range m.Versions() {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := m.BuildIndex(ctx, version); err != nil {
+			continue
+		}
+		indexed = append(indexed, version)
 	}
+	return indexed
+}
 
-	// Read all files
-	entries, err := os.ReadDir(versionPath)
-	if err != nil {
-		return "", fmt.Errorf("read directory: %w", err)
+// RefreshIndex discards cached search results and rebuilds the index for a
+// version, typically after its files changed on disk
+func (m *Manager) RefreshIndex(ctx context.Context, version string) error {
+	m.cache.Clear()
+	m.index.Remove(version)
+	return m.BuildIndex(ctx, version)
+}
+
+// versionIndex returns the search index for a version, building it on first use
+func (m *Manager) versionIndex(ctx context.Context, version string) (*versionIndex, error) {
+	if idx, ok := m.index.get(version); ok {
+		return idx, nil
 	}
 
-	// Search results
-	type searchResult struct {
-		filename string
-		matches  int
+	if err := m.BuildIndex(ctx, version); err != nil {
+		return nil, err
 	}
-	var results []searchResult
 
-	queryLower := strings.ToLower(query)
+	idx, _ := m.index.get(version)
+	return idx, nil
+}
 
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
-			continue
-		}
+// Search returns sections matching the query ranked by relevance.
+// A limit of zero or less returns all hits. The hits are shared with the
+// search cache and must not be modified.
+func (m *Manager) Search(ctx context.Context, query, version string, limit int) ([]SearchHit, error) {
+	if version == "" {
+		version = m.defaultVersion
+	}
 
-		filePath := filepath.Join(versionPath, entry.Name())
-		data, err := os.ReadFile(filePath)
+	cacheKey := fmt.Sprintf("search:%s:%s", version, query)
+	var hits []SearchHit
+	if cached, found := m.cache.GetSearch(cacheKey); found {
+		hits = cached.([]SearchHit)
+	} else {
+		idx, err := m.versionIndex(ctx, version)
 		if err != nil {
-			continue
+			return nil, err
 		}
 
-		content := strings.ToLower(string(data))
-		matches := strings.Count(content, queryLower)
-
-		if matches > 0 {
-			results = append(results, searchResult{
-				filename: entry.Name(),
-				matches:  matches,
-			})
+		hits = idx.search(query)
+		for i := range hits {
+			hits[i].Version = version
 		}
+		m.cache.SetSearch(cacheKey, hits)
+	}
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
 	}
 
-	// Format results
+	return hits, nil
+}
+
+// FormatSearchResults renders the hits of a single-version search as Markdown, grouped by file
+func FormatSearchResults(query, version string, hits []SearchHit) string {
+	files := GroupHitsByFile(hits)
+
 	var output strings.Builder
 	output.WriteString(fmt.Sprintf("# Search Results for '%s' in Laravel %s\n\n", query, version))
 
-	if len(results) == 0 {
+	if len(files) == 0 {
 		output.WriteString("No matches found.\n")
 	} else {
-		output.WriteString(fmt.Sprintf("Found %d files with matches:\n\n", len(results)))
-		for _, result := range results {
-			output.WriteString(fmt.Sprintf("- **%s**: %d matches\n", result.filename, result.matches))
+		output.WriteString(fmt.Sprintf("Found %d files with matches:\n\n", len(files)))
+		for _, file := range files {
+			output.WriteString(fmt.Sprintf("- **%s** (score %.2f)\n", file.File, file.Score))
+			for i, sec := range file.Sections {
+				if i >= maxSectionsPerFile {
+					output.WriteString(fmt.Sprintf("  - ...and %d more sections\n", len(file.Sections)-i))
+					break
+				}
+				output.WriteString(fmt.Sprintf("  - %s#%s - %s (%.2f)\n", file.File, sec.Anchor, sec.Section, sec.Score))
+			}
 		}
 	}
 
-	resultStr := output.String()
+	return output.String()
+}
 
-	// Cache results
-	m.cache.SetSearch(cacheKey, resultStr)
+// ContextMatch is a matching section with the text around the query
+type ContextMatch struct {
+	Passage
+	Text string
+}
 
-	return resultStr, nil
+// ContextResults holds the context matches whose newest occurrence is in Version
+type ContextResults struct {
+	Version string
+	Matches []ContextMatch
 }
 
-// SearchWithContext searches and returns results with surrounding context
-func (m *Manager) SearchWithContext(query, version string, contextLength int) (string, error) {
+// SearchContext returns the passages around the query in the relevant sections
+// of a version. An empty version searches all versions, listing each identical
+// passage once under the newest version containing it.
+func (m *Manager) SearchContext(ctx context.Context, query, version string, contextLength int) ([]ContextResults, error) {
 	if version == "" {
-		version = m.defaultVersion
-	}
-
-	versionPath := filepath.Join(m.DocsPath, version)
-	if _, err := os.Stat(versionPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("version %s not found", version)
+		return m.searchContextAllVersions(ctx, query, contextLength)
 	}
 
-	entries, err := os.ReadDir(versionPath)
+	hits, err := m.Search(ctx, query, version, 0)
 	if err != nil {
-		return "", fmt.Errorf("read directory: %w", err)
-	}
-
-	type contextMatch struct {
-		filename string
-		context  string
-		position int
+		return nil, err
 	}
-	var matches []contextMatch
 
-	queryLower := strings.ToLower(query)
+	results := ContextResults{Version: version}
+	reader := newSectionReader(m)
 
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
-			continue
+	// Hits are already ranked; read each section and cut a passage around the query
+	for _, hit := range hits {
+		if len(results.Matches) >= maxContextResults {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
 
-		filePath := filepath.Join(versionPath, entry.Name())
-		data, err := os.ReadFile(filePath)
-		if err != nil {
+		text, found := reader.passage(ctx, hit, query, contextLength)
+		if !found {
 			continue
 		}
 
-		content := string(data)
-		contentLower := strings.ToLower(content)
-
-		// Find all occurrences
-		index := 0
-		for {
-			pos := strings.Index(contentLower[index:], queryLower)
-			if pos == -1 {
-				break
-			}
-
-			actualPos := index + pos
-
-			// Extract context
-			start := actualPos - contextLength
-			if start < 0 {
-				start = 0
-			}
-			end := actualPos + len(query) + contextLength
-			if end > len(content) {
-				end = len(content)
-			}
+		results.Matches = append(results.Matches, ContextMatch{
+			Passage: Passage{Hit: hit, Versions: []string{version}},
+			Text:    text,
+		})
+	}
 
-			contextStr := content[start:end]
-			// Add ellipsis if truncated
-			if start > 0 {
-				contextStr = "..." + contextStr
-			}
-			if end < len(content) {
-				contextStr = contextStr + "..."
-			}
+	return []ContextResults{results}, nil
+}
 
-			matches = append(matches, contextMatch{
-				filename: entry.Name(),
-				context:  contextStr,
-				position: actualPos,
-			})
+// FormatContextResults renders SearchContext results as Markdown
+func FormatContextResults(query, version string, results []ContextResults) string {
+	if version == "" {
+		return formatContextAllVersions(query, results)
+	}
 
-			index = actualPos + len(query)
-			if index >= len(content) {
-				break
-			}
-		}
+	var matches []ContextMatch
+	if len(results) > 0 {
+		matches = results[0].Matches
 	}
 
-	// Format results
 	var output strings.Builder
 	output.WriteString(fmt.Sprintf("# Search Results with Context for '%s' in Laravel %s\n\n", query, version))
 
 	if len(matches) == 0 {
 		output.WriteString("No matches found.\n")
 	} else {
-		output.WriteString(fmt.Sprintf("Found %d matches:\n\n", len(matches)))
+		output.WriteString(fmt.Sprintf("Found %d matching sections:\n\n", len(matches)))
 
-		currentFile := ""
 		for _, match := range matches {
-			if match.filename != currentFile {
-				output.WriteString(fmt.Sprintf("\n### %s\n\n", match.filename))
-				currentFile = match.filename
-			}
-			output.WriteString(fmt.Sprintf("```\n%s\n```\n\n", match.context))
+			output.WriteString(fmt.Sprintf("### %s#%s - %s\n\n", match.Hit.File, match.Hit.Anchor, match.Hit.Section))
+			output.WriteString(match.Text)
+			output.WriteString("\n\n---\n\n")
+		}
+	}
+
+	return output.String()
+}
+
+// FormatStructure renders the headings of a documentation file as a Markdown table of contents
+func FormatStructure(filename, version string, sections []Section) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("# Structure of %s (Laravel %s)\n\n", filename, version))
+
+	for _, sec := range sections {
+		if sec.Level == 0 {
+			continue
 		}
+		indent := strings.Repeat("  ", sec.Level-1)
+		output.WriteString(fmt.Sprintf("%s- %s (#%s)\n", indent, sec.Title, sec.Anchor))
 	}
 
-	return output.String(), nil
+	return output.String()
 }
 
-// GetStructure extracts the table of contents from a documentation file
-func (m *Manager) GetStructure(filename, version string) (string, error) {
+// GetSection returns a single section of a documentation file. The section may
+// be referenced by heading text, slug or the anchor Laravel docs embed.
+func (m *Manager) GetSection(ctx context.Context, version, filename, ref string, includeSubsections bool) (*SectionContent, error) {
 	if version == "" {
 		version = m.defaultVersion
 	}
 
-	content, err := m.ReadDoc(version, filename)
+	content, err := m.ReadDoc(ctx, version, filename)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	section, ok := ExtractSection(content, ref, includeSubsections)
+	if !ok {
+		return nil, fmt.Errorf("section not found: %s in %s", ref, filename)
+	}
+	return section, nil
+}
+
+// FormatSection renders a section with its source and breadcrumb as Markdown
+func FormatSection(version, filename string, section *SectionContent) string {
 	var output strings.Builder
-	output.WriteString(fmt.Sprintf("# Structure of %s (Laravel %s)\n\n", filename, version))
+	output.WriteString(fmt.Sprintf("**Source:** %s#%s (Laravel %s)\n", filename, section.Section.Anchor, version))
+	if len(section.Breadcrumb) > 0 {
+		output.WriteString(fmt.Sprintf("**Breadcrumb:** %s > %s\n", strings.Join(section.Breadcrumb, " > "), section.Section.Title))
+	}
+	output.WriteString("\n")
+	output.WriteString(section.Content)
+	output.WriteString("\n")
 
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
+	return output.String()
+}
 
-		// Extract headers (markdown style)
-		if strings.HasPrefix(trimmed, "#") {
-			// Count header level
-			level := 0
-			for i := 0; i < len(trimmed) && trimmed[i] == '#'; i++ {
-				level++
-			}
+// FormatCategoryFiles renders the files of a category as Markdown
+func FormatCategoryFiles(category, version string, files []string) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("# Documentation for Category: %s (Laravel %s)\n\n", category, version))
 
-			if level > 0 && level <= 6 {
-				headerText := strings.TrimSpace(trimmed[level:])
-				indent := strings.Repeat("  ", level-1)
-				output.WriteString(fmt.Sprintf("%s- %s\n", indent, headerText))
-			}
+	if len(files) == 0 {
+		output.WriteString(fmt.Sprintf("No documentation files found for category '%s'.\n\n", category))
+		output.WriteString("Available categories: frontend, database, authentication, testing, security, deployment, packages\n")
+	} else {
+		output.WriteString(fmt.Sprintf("Found %d files:\n\n", len(files)))
+		for _, file := range files {
+			output.WriteString(fmt.Sprintf("- %s\n", file))
 		}
 	}
 
-	return output.String(), nil
+	return output.String()
 }
 
-// BrowseByCategory returns documentation files related to a specific category
-func (m *Manager) BrowseByCategory(category, version string) (string, error) {
+// CategoryFiles returns the documentation files belonging to a category
+func (m *Manager) CategoryFiles(ctx context.Context, category, version string) ([]string, error) {
 	if version == "" {
 		version = m.defaultVersion
 	}
 
-	files, err := m.ListDocs(version)
+	files, err := m.ListDocs(ctx, version)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Category mappings
@@ -356,68 +489,115 @@ func (m *Manager) BrowseByCategory(category, version string) (string, error) {
 		}
 	}
 
-	var output strings.Builder
-	output.WriteString(fmt.Sprintf("# Documentation for Category: %s (Laravel %s)\n\n", category, version))
+	return matches, nil
+}
 
-	if len(matches) == 0 {
-		output.WriteString(fmt.Sprintf("No documentation files found for category '%s'.\n\n", category))
-		output.WriteString("Available categories: frontend, database, authentication, testing, security, deployment, packages\n")
-	} else {
-		output.WriteString(fmt.Sprintf("Found %d files:\n\n", len(matches)))
-		for _, file := range matches {
-			output.WriteString(fmt.Sprintf("- %s\n", file))
+// VersionInfo describes the documentation stored for one version
+type VersionInfo struct {
+	Version     string
+	LastUpdated time.Time
+	FileCount   int
+	CommitSHA   string    // empty when the version was not synced by the updater
+	LastSynced  time.Time // zero when the version was not synced by the updater
+}
+
+// VersionInfos returns metadata about one version, or every version present on disk when version is empty
+func (m *Manager) VersionInfos(ctx context.Context, version string) ([]VersionInfo, error) {
+	versions := m.Versions()
+	if version != "" {
+		versions = []string{version}
+	}
+
+	var infos []VersionInfo
+	for _, ver := range versions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
+
+		versionPath := filepath.Join(m.DocsPath, ver)
+		info, err := os.Stat(versionPath)
+		if err != nil {
+			if version != "" {
+				return nil, fmt.Errorf("version %s not found", version)
+			}
+			continue
+		}
+
+		files, err := m.ListDocs(ctx, ver)
+		if err != nil {
+			if version != "" {
+				return nil, err
+			}
+			continue
+		}
+
+		vi := VersionInfo{
+			Version:     ver,
+			LastUpdated: info.ModTime(),
+			FileCount:   len(files),
+		}
+		if data, err := os.ReadFile(filepath.Join(versionPath, ".metadata.json")); err == nil {
+			var metadata models.DocMetadata
+			if json.Unmarshal(data, &metadata) == nil {
+				vi.CommitSHA = metadata.CommitSHA
+				vi.LastSynced = metadata.SyncTime
+			}
+		}
+		infos = append(infos, vi)
 	}
 
-	return output.String(), nil
+	return infos, nil
 }
 
-// GetInfo returns metadata about documentation versions
-func (m *Manager) GetInfo(version string) (string, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
+// FormatVersionInfos renders VersionInfos results as Markdown. An empty version
+// lists every version in infos.
+func FormatVersionInfos(version string, infos []VersionInfo) string {
 	var output strings.Builder
 
 	if version == "" {
 		// Show all versions
 		output.WriteString("# Laravel Documentation Information\n\n")
 
-		for _, ver := range m.versions {
-			versionPath := filepath.Join(m.DocsPath, ver)
-			info, err := os.Stat(versionPath)
-			if err != nil {
-				continue
-			}
-
-			files, err := m.ListDocs(ver)
-			if err != nil {
-				continue
-			}
-
-			output.WriteString(fmt.Sprintf("## Version %s\n", ver))
-			output.WriteString(fmt.Sprintf("Last updated: %s\n", info.ModTime().Format("2006-01-02 15:04:05")))
-			output.WriteString(fmt.Sprintf("Files: %d documentation files\n\n", len(files)))
+		for _, info := range infos {
+			output.WriteString(fmt.Sprintf("## Version %s\n", info.Version))
+			output.WriteString(fmt.Sprintf("Last updated: %s\n", info.LastUpdated.Format("2006-01-02 15:04:05")))
+			output.WriteString(fmt.Sprintf("Files: %d documentation files\n\n", info.FileCount))
 		}
-	} else {
+	} else if len(infos) > 0 {
 		// Show specific version
-		versionPath := filepath.Join(m.DocsPath, version)
-		info, err := os.Stat(versionPath)
-		if err != nil {
-			return "", fmt.Errorf("version %s not found", version)
-		}
+		info := infos[0]
+		output.WriteString(fmt.Sprintf("# Laravel %s Documentation\n\n", version))
+		output.WriteString(fmt.Sprintf("Last updated: %s\n", info.LastUpdated.Format("2006-01-02 15:04:05")))
+		output.WriteString(fmt.Sprintf("Files: %d documentation files\n", info.FileCount))
+	}
 
-		files, err := m.ListDocs(version)
-		if err != nil {
-			return "", err
-		}
+	return output.String()
+}
 
-		output.WriteString(fmt.Sprintf("# Laravel %s Documentation\n\n", version))
-		output.WriteString(fmt.Sprintf("Last updated: %s\n", info.ModTime().Format("2006-01-02 15:04:05")))
-		output.WriteString(fmt.Sprintf("Files: %d documentation files\n", len(files)))
+// waitForSwap waits while an update swaps a new copy of version into place and
+// reports whether the version directory exists afterwards. The updater moves the
+// live copy aside before renaming the new one in, so for a moment neither is
+// found under the version name.
+func (m *Manager) waitForSwap(version string) bool {
+	if !models.IsVersionName(version) {
+		return false
 	}
 
-	return output.String(), nil
+	versionPath := filepath.Join(m.DocsPath, version)
+	backupPath := filepath.Join(m.DocsPath, models.SwapBackupPrefix+version)
+
+	for i := 0; i < swapWaitAttempts; i++ {
+		if _, err := os.Stat(versionPath); err == nil {
+			return true
+		}
+		if _, err := os.Stat(backupPath); err != nil {
+			// No swap in progress, or it finished since the version was checked
+			_, err := os.Stat(versionPath)
+			return err == nil
+		}
+		time.Sleep(swapWaitDelay)
+	}
+	return false
 }
 
 // ClearCache clears all cached documentation
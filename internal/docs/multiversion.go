@@ -0,0 +1,322 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxPassagesPerVersion limits how many passages are listed per version in multi-version results
+const maxPassagesPerVersion = 10
+
+// Passage is a matching section together with every version that contains it verbatim
+type Passage struct {
+	Hit      SearchHit // taken from the newest version containing the passage
+	Versions []string  // versions containing the identical passage, newest first
+
+	// FirstVersion is the oldest searched version containing the passage and
+	// FirstChange says how it got there ("added" or "changed"). FirstChange is
+	// empty when the passage already exists in the oldest searched version.
+	FirstVersion string
+	FirstChange  string
+
+	// GoneIn is the first newer version that no longer contains the passage and
+	// GoneChange says why ("removed" or "changed"). Both are empty when the
+	// passage is still in the newest searched version.
+	GoneIn     string
+	GoneChange string
+}
+
+// VersionResults holds the passages whose newest occurrence is in Version
+type VersionResults struct {
+	Version  string
+	Matches  int // matching sections in this version, including duplicates
+	Passages []Passage
+}
+
+// AvailableVersions returns the known versions present on disk, newest first
+func (m *Manager) AvailableVersions() []string {
+	var available []string
+	for _, version := range m.Versions() {
+		info, err := os.Stat(filepath.Join(m.DocsPath, version))
+		if err == nil && info.IsDir() {
+			available = append(available, version)
+		}
+	}
+	return available
+}
+
+// SearchAllVersions searches every available version and deduplicates sections
+// whose text is identical across versions. The results are shared with the
+// search cache and must not be modified.
+func (m *Manager) SearchAllVersions(ctx context.Context, query string) ([]VersionResults, error) {
+	cacheKey := fmt.Sprintf("search:all:%s", query)
+	if cached, found := m.cache.GetSearch(cacheKey); found {
+		return cached.([]VersionResults), nil
+	}
+
+	results, err := m.searchAllVersions(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	m.cache.SetSearch(cacheKey, results)
+	return results, nil
+}
+
+// searchAllVersions runs an uncached SearchAllVersions
+func (m *Manager) searchAllVersions(ctx context.Context, query string) ([]VersionResults, error) {
+	versions := m.AvailableVersions()
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no documentation versions found")
+	}
+
+	indexes := make(map[string]*versionIndex)
+	results := make([]VersionResults, len(versions))
+	passages := make(map[string]*Passage)
+	var order []string
+
+	// Versions are newest first, so the first occurrence of a passage is its newest
+	for i, version := range versions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		idx, err := m.versionIndex(ctx, version)
+		if err != nil {
+			return nil, err
+		}
+		indexes[version] = idx
+
+		hits := idx.search(query)
+		results[i] = VersionResults{Version: version, Matches: len(hits)}
+
+		for _, hit := range hits {
+			hit.Version = version
+			key := hit.File + "#" + hit.Anchor + "@" + hit.hash
+
+			if p, ok := passages[key]; ok {
+				if p.Versions[len(p.Versions)-1] != version {
+					p.Versions = append(p.Versions, version)
+				}
+				continue
+			}
+			passages[key] = &Passage{Hit: hit, Versions: []string{version}}
+			order = append(order, key)
+		}
+	}
+
+	position := make(map[string]int, len(versions))
+	for i, version := range versions {
+		position[version] = i
+	}
+
+	for _, key := range order {
+		p := passages[key]
+		ref := p.Hit.File + "#" + p.Hit.Anchor
+
+		newest := position[p.Versions[0]]
+		oldest := position[p.Versions[len(p.Versions)-1]]
+
+		p.FirstVersion = versions[oldest]
+		if oldest < len(versions)-1 {
+			p.FirstChange = "added"
+			if _, ok := indexes[versions[oldest+1]].hashes[ref]; ok {
+				p.FirstChange = "changed"
+			}
+		}
+
+		if newest > 0 {
+			p.GoneIn = versions[newest-1]
+			p.GoneChange = "removed"
+			if _, ok := indexes[p.GoneIn].hashes[ref]; ok {
+				p.GoneChange = "changed"
+			}
+		}
+
+		results[newest].Passages = append(results[newest].Passages, *p)
+	}
+
+	return results, nil
+}
+
+// FormatAllVersionsResults renders SearchAllVersions results as Markdown
+func FormatAllVersionsResults(query string, results []VersionResults) string {
+	var output strings.Builder
+	writeAllVersionsHeader(&output, fmt.Sprintf("# Search Results for '%s' across Laravel versions", query), versionNames(results))
+
+	for _, vr := range results {
+		output.WriteString(fmt.Sprintf("## Laravel %s\n\n", vr.Version))
+
+		if vr.Matches == 0 {
+			output.WriteString("No matches found.\n\n")
+			continue
+		}
+		output.WriteString(fmt.Sprintf("%d matching sections, %d unique to this version's text:\n\n", vr.Matches, len(vr.Passages)))
+
+		for i, p := range vr.Passages {
+			if i >= maxPassagesPerVersion {
+				output.WriteString(fmt.Sprintf("- ...and %d more passages\n", len(vr.Passages)-i))
+				break
+			}
+			output.WriteString(fmt.Sprintf("- %s#%s - %s (%.2f)%s\n", p.Hit.File, p.Hit.Anchor, p.Hit.Section, p.Hit.Score, lineageNote(p)))
+		}
+		output.WriteString("\n")
+	}
+
+	return output.String()
+}
+
+// searchContextAllVersions returns passages with context for every version,
+// keeping each identical passage only once
+func (m *Manager) searchContextAllVersions(ctx context.Context, query string, contextLength int) ([]ContextResults, error) {
+	results, err := m.SearchAllVersions(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := newSectionReader(m)
+	contextResults := make([]ContextResults, len(results))
+
+	for i, vr := range results {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		contextResults[i].Version = vr.Version
+
+		for _, p := range vr.Passages {
+			if len(contextResults[i].Matches) >= maxPassagesPerVersion {
+				break
+			}
+
+			text, found := reader.passage(ctx, p.Hit, query, contextLength)
+			if !found {
+				continue
+			}
+			contextResults[i].Matches = append(contextResults[i].Matches, ContextMatch{Passage: p, Text: text})
+		}
+	}
+
+	return contextResults, nil
+}
+
+// formatContextAllVersions renders all-versions SearchContext results as Markdown
+func formatContextAllVersions(query string, results []ContextResults) string {
+	versions := make([]string, len(results))
+	for i, cr := range results {
+		versions[i] = cr.Version
+	}
+
+	var output strings.Builder
+	writeAllVersionsHeader(&output, fmt.Sprintf("# Search Results with Context for '%s' across Laravel versions", query), versions)
+
+	for _, cr := range results {
+		output.WriteString(fmt.Sprintf("## Laravel %s\n\n", cr.Version))
+
+		for _, match := range cr.Matches {
+			output.WriteString(fmt.Sprintf("### %s#%s - %s%s\n\n", match.Hit.File, match.Hit.Anchor, match.Hit.Section, lineageNote(match.Passage)))
+			output.WriteString(match.Text)
+			output.WriteString("\n\n---\n\n")
+		}
+
+		if len(cr.Matches) == 0 {
+			output.WriteString("No passages unique to this version.\n\n")
+		}
+	}
+
+	return output.String()
+}
+
+// versionNames returns the versions of results in order
+func versionNames(results []VersionResults) []string {
+	versions := make([]string, len(results))
+	for i, vr := range results {
+		versions[i] = vr.Version
+	}
+	return versions
+}
+
+// writeAllVersionsHeader writes the title and list of searched versions
+func writeAllVersionsHeader(output *strings.Builder, title string, versions []string) {
+	output.WriteString(title + "\n\n")
+	output.WriteString(fmt.Sprintf("Searched %d versions: %s\n\n", len(versions), strings.Join(versions, ", ")))
+	output.WriteString("Identical passages are listed once under the newest version containing them.\n\n")
+}
+
+// lineageNote describes where else a passage appears and when it appeared or disappeared
+func lineageNote(p Passage) string {
+	var notes []string
+
+	if len(p.Versions) > 1 {
+		notes = append(notes, "also in "+strings.Join(p.Versions[1:], ", "))
+	}
+	if p.FirstChange != "" {
+		notes = append(notes, fmt.Sprintf("%s in %s", p.FirstChange, p.FirstVersion))
+	}
+	if p.GoneChange != "" {
+		notes = append(notes, fmt.Sprintf("%s in %s", p.GoneChange, p.GoneIn))
+	}
+
+	if len(notes) == 0 {
+		return ""
+	}
+	return " - _" + strings.Join(notes, "; ") + "_"
+}
+
+// sectionReader caches parsed sections while passages are extracted
+type sectionReader struct {
+	m     *Manager
+	files map[string]map[string]Section
+}
+
+// newSectionReader creates a reader backed by the manager's file cache
+func newSectionReader(m *Manager) *sectionReader {
+	return &sectionReader{
+		m:     m,
+		files: make(map[string]map[string]Section),
+	}
+}
+
+// passage cuts the text around the query out of the section a hit points to,
+// or its opening paragraph when the query does not appear literally
+func (r *sectionReader) passage(ctx context.Context, hit SearchHit, query string, contextLength int) (string, bool) {
+	key := hit.Version + ":" + hit.File
+
+	sections, ok := r.files[key]
+	if !ok {
+		content, err := r.m.ReadDoc(ctx, hit.Version, hit.File)
+		if err != nil {
+			return "", false
+		}
+		sections = make(map[string]Section)
+		for _, sec := range ParseSections(content) {
+			if _, exists := sections[sec.Anchor]; !exists {
+				sections[sec.Anchor] = sec
+			}
+		}
+		r.files[key] = sections
+	}
+
+	sec, ok := sections[hit.Anchor]
+	if !ok {
+		return "", false
+	}
+
+	// Prefer the exact phrase, then fall back to the individual words
+	passage, found := snippet(sec.Body, query, contextLength)
+	for _, word := range strings.Fields(query) {
+		if found {
+			break
+		}
+		passage, found = snippet(sec.Body, word, contextLength)
+	}
+
+	// Hits matched through stemming or the title have no literal match in the body
+	if !found {
+		passage, found = openingParagraph(sec.Body, contextLength)
+	}
+
+	return passage, found
+}
@@ -0,0 +1,401 @@
+package docs
+
+import (
+	"regexp"
+	"strings"
+)
+
+// anchorPattern matches the <a name="..."></a> anchors Laravel docs place above headings
+var anchorPattern = regexp.MustCompile(`^<a\s+name="([^"]+)"\s*>\s*</a>$`)
+
+// Section is a markdown heading and the content below it up to the next heading
+type Section struct {
+	Title     string
+	Level     int // 0 for content before the first heading
+	Anchor    string
+	StartLine int // line of the heading
+	EndLine   int // first line after the section body
+	Body      string
+}
+
+// ParseSections splits markdown content into sections at every heading outside of code fences
+func ParseSections(content string) []Section {
+	lines := strings.Split(content, "\n")
+
+	var sections []Section
+	current := Section{}
+	bodyStart := 0
+	pendingAnchor := ""
+	anchorLine := 0
+	inFence := false
+
+	flush := func(end int) {
+		current.EndLine = end
+		current.Body = strings.Join(lines[bodyStart:end], "\n")
+		if current.Level > 0 || strings.TrimSpace(current.Body) != "" {
+			sections = append(sections, current)
+		}
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if isFence(trimmed) {
+			inFence = !inFence
+			pendingAnchor = ""
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		if m := anchorPattern.FindStringSubmatch(trimmed); m != nil {
+			pendingAnchor = m[1]
+			anchorLine = i
+			continue
+		}
+
+		level, text, ok := parseHeading(trimmed)
+		if !ok {
+			if trimmed != "" {
+				pendingAnchor = ""
+			}
+			continue
+		}
+
+		// The anchor line belongs to the heading below it, not the previous section
+		if pendingAnchor != "" {
+			flush(anchorLine)
+		} else {
+			flush(i)
+		}
+
+		anchor := pendingAnchor
+		if anchor == "" {
+			anchor = Slugify(text)
+		}
+		current = Section{
+			Title:     text,
+			Level:     level,
+			Anchor:    anchor,
+			StartLine: i,
+		}
+		bodyStart = i + 1
+		pendingAnchor = ""
+	}
+	flush(len(lines))
+
+	return sections
+}
+
+// SectionContent is a resolved section of a documentation file
+type SectionContent struct {
+	Section    Section
+	Breadcrumb []string // titles of enclosing headings, outermost first
+	Content    string   // heading line and body
+}
+
+// FindSection resolves a section reference (heading text, slug or anchor)
+// and returns its index in sections
+func FindSection(sections []Section, ref string) (int, bool) {
+	ref = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(ref), "#"))
+	if ref == "" {
+		return -1, false
+	}
+	slug := Slugify(ref)
+
+	// Exact anchors win over slugs, slugs over case-insensitive titles
+	for i, sec := range sections {
+		if sec.Level > 0 && sec.Anchor == ref {
+			return i, true
+		}
+	}
+	for i, sec := range sections {
+		if sec.Level > 0 && (sec.Anchor == slug || Slugify(sec.Title) == slug) {
+			return i, true
+		}
+	}
+	for i, sec := range sections {
+		if sec.Level > 0 && strings.EqualFold(sec.Title, ref) {
+			return i, true
+		}
+	}
+
+	return -1, false
+}
+
+// ExtractSection returns the section of markdown content a reference (heading
+// text, slug or anchor) points at
+func ExtractSection(content, ref string, includeSubsections bool) (*SectionContent, bool) {
+	sections := ParseSections(content)
+	i, ok := FindSection(sections, ref)
+	if !ok {
+		return nil, false
+	}
+
+	lines := strings.Split(content, "\n")
+	end := sectionEnd(sections, i, includeSubsections)
+
+	return &SectionContent{
+		Section:    sections[i],
+		Breadcrumb: breadcrumb(sections, i),
+		Content:    strings.TrimSpace(strings.Join(lines[sections[i].StartLine:end], "\n")),
+	}, true
+}
+
+// breadcrumb returns the titles of the headings enclosing sections[i]
+func breadcrumb(sections []Section, i int) []string {
+	var path []string
+	level := sections[i].Level
+
+	for j := i - 1; j >= 0 && level > 1; j-- {
+		if sections[j].Level > 0 && sections[j].Level < level {
+			path = append([]string{sections[j].Title}, path...)
+			level = sections[j].Level
+		}
+	}
+
+	return path
+}
+
+// sectionEnd returns the line where sections[i] ends, optionally including its subsections
+func sectionEnd(sections []Section, i int, includeSubsections bool) int {
+	end := sections[i].EndLine
+	if !includeSubsections {
+		return end
+	}
+
+	for j := i + 1; j < len(sections) && sections[j].Level > sections[i].Level; j++ {
+		end = sections[j].EndLine
+	}
+	return end
+}
+
+// parseHeading returns the level and text of a markdown ATX heading
+func parseHeading(trimmed string) (int, string, bool) {
+	if !strings.HasPrefix(trimmed, "#") {
+		return 0, "", false
+	}
+
+	// Count header level
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+
+	if level > 6 || (level < len(trimmed) && trimmed[level] != ' ') {
+		return 0, "", false
+	}
+
+	text := strings.TrimSpace(trimmed[level:])
+	if text == "" {
+		return 0, "", false
+	}
+	return level, text, true
+}
+
+// isFence reports whether a trimmed line opens or closes a fenced code block
+func isFence(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+// Slugify converts heading text to a GitHub-style anchor
+func Slugify(text string) string {
+	var b strings.Builder
+	lastDash := false
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r > 127:
+			b.WriteRune(r)
+			lastDash = false
+		case r == ' ' || r == '-' || r == '_':
+			if !lastDash && b.Len() > 0 {
+				b.WriteRune('-')
+				lastDash = true
+			}
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// block is a paragraph or a whole fenced code block
+type block struct {
+	text   string
+	isCode bool
+}
+
+// splitBlocks splits section text into paragraphs, keeping fenced code blocks intact
+func splitBlocks(text string) []block {
+	var blocks []block
+	var current []string
+	inFence := false
+
+	flush := func(isCode bool) {
+		if len(current) > 0 {
+			blocks = append(blocks, block{text: strings.Join(current, "\n"), isCode: isCode})
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if isFence(trimmed) {
+			if !inFence {
+				flush(false)
+				current = append(current, line)
+				inFence = true
+			} else {
+				current = append(current, line)
+				flush(true)
+				inFence = false
+			}
+			continue
+		}
+
+		if inFence {
+			current = append(current, line)
+			continue
+		}
+
+		if trimmed == "" || anchorPattern.MatchString(trimmed) {
+			flush(false)
+			continue
+		}
+		current = append(current, line)
+	}
+	flush(inFence)
+
+	return blocks
+}
+
+// snippet extracts the passage around the first occurrence of term in text.
+// Whole paragraphs and code blocks are kept together; neighbouring blocks are
+// added while they fit in contextLength characters on each side.
+func snippet(text, term string, contextLength int) (string, bool) {
+	contextLength = max(contextLength, 0)
+	termLower := strings.ToLower(term)
+	blocks := splitBlocks(text)
+
+	hit := -1
+	for i, b := range blocks {
+		if strings.Contains(strings.ToLower(b.text), termLower) {
+			hit = i
+			break
+		}
+	}
+	if hit == -1 {
+		return "", false
+	}
+
+	parts := []string{trimBlock(blocks[hit], termLower, contextLength)}
+
+	budget := contextLength
+	for i := hit - 1; i >= 0 && len(blocks[i].text) <= budget; i-- {
+		parts = append([]string{blocks[i].text}, parts...)
+		budget -= len(blocks[i].text)
+	}
+
+	budget = contextLength
+	for i := hit + 1; i < len(blocks) && len(blocks[i].text) <= budget; i++ {
+		parts = append(parts, blocks[i].text)
+		budget -= len(blocks[i].text)
+	}
+
+	return strings.Join(parts, "\n\n"), true
+}
+
+// openingParagraph returns the first prose block of a section, shortened to
+// about twice contextLength, falling back to its first block of any kind
+func openingParagraph(text string, contextLength int) (string, bool) {
+	contextLength = max(contextLength, 0)
+	blocks := splitBlocks(text)
+	if len(blocks) == 0 {
+		return "", false
+	}
+
+	for _, b := range blocks {
+		if !b.isCode {
+			return trimBlock(b, "", contextLength), true
+		}
+	}
+	return trimBlock(blocks[0], "", contextLength), true
+}
+
+// trimBlock shortens an oversized block around the match without breaking its structure
+func trimBlock(b block, termLower string, contextLength int) string {
+	limit := 2*contextLength + len(termLower)
+	if len(b.text) <= limit {
+		return b.text
+	}
+
+	if !b.isCode {
+		pos := strings.Index(strings.ToLower(b.text), termLower)
+		start := pos - contextLength
+		end := pos + len(termLower) + contextLength
+		prefix, suffix := "", ""
+		if start > 0 {
+			if space := strings.IndexByte(b.text[start:pos], ' '); space != -1 {
+				start += space + 1
+			}
+			prefix = "..."
+		} else {
+			start = 0
+		}
+		if end < len(b.text) {
+			if space := strings.LastIndexByte(b.text[pos:end], ' '); space > len(termLower) {
+				end = pos + space
+			}
+			suffix = "..."
+		} else {
+			end = len(b.text)
+		}
+		return prefix + b.text[start:end] + suffix
+	}
+
+	// Keep the fence lines and the code lines nearest the match
+	lines := strings.Split(b.text, "\n")
+	if len(lines) < 3 {
+		return b.text
+	}
+	open, code, closing := lines[0], lines[1:len(lines)-1], lines[len(lines)-1]
+	if !isFence(strings.TrimSpace(closing)) {
+		code, closing = lines[1:], "```"
+	}
+
+	hit := 0
+	for i, line := range code {
+		if strings.Contains(strings.ToLower(line), termLower) {
+			hit = i
+			break
+		}
+	}
+
+	start, end := hit, hit+1
+	size := len(code[hit])
+	for size < limit && (start > 0 || end < len(code)) {
+		if start > 0 {
+			start--
+			size += len(code[start]) + 1
+		}
+		if end < len(code) && size < limit {
+			size += len(code[end]) + 1
+			end++
+		}
+	}
+
+	var out []string
+	out = append(out, open)
+	if start > 0 {
+		out = append(out, "// ...")
+	}
+	out = append(out, code[start:end]...)
+	if end < len(code) {
+		out = append(out, "// ...")
+	}
+	out = append(out, closing)
+
+	return strings.Join(out, "\n")
+}
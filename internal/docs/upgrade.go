@@ -0,0 +1,262 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/models"
+)
+
+// upgradeGuideFile is the upgrade guide shipped with every Laravel version
+const upgradeGuideFile = "upgrade.md"
+
+var (
+	// likelihoodPattern matches "**Likelihood Of Impact: Medium**"
+	likelihoodPattern = regexp.MustCompile(`(?i)\*\*Likelihood Of Impact:\s*([^*]+?)\s*\*\*`)
+
+	// upgradeHeadingPattern matches "Upgrading To 12.0 From 11.x"
+	upgradeHeadingPattern = regexp.MustCompile(`(?i)^Upgrading To (\d+)\.`)
+)
+
+// impactRanks orders the impact levels used by Laravel upgrade guides
+var impactRanks = map[string]int{
+	"optional": 0,
+	"very low": 1,
+	"low":      2,
+	"medium":   3,
+	"high":     4,
+}
+
+// ImpactRank orders the impact levels used by Laravel upgrade guides, from
+// optional (0) to high (4). It reports false for an unknown level, which ranks
+// with optional.
+func ImpactRank(impact string) (int, bool) {
+	rank, ok := impactRanks[strings.ToLower(impact)]
+	return rank, ok
+}
+
+// UpgradeItem is a single change described in an upgrade guide
+type UpgradeItem struct {
+	TargetVersion string // version the change applies when upgrading to, e.g. "12.x"
+	Component     string // e.g. "Queues"
+	Title         string
+	Anchor        string
+	Impact        string // lowercase likelihood of impact, e.g. "high"
+	Content       string
+}
+
+// UpgradeFilter narrows the upgrade items collected by PlanUpgrade
+type UpgradeFilter struct {
+	MinImpact string // only items at or above this impact
+	Component string // only items whose component or title matches
+}
+
+// ParseUpgradeGuide extracts the upgrade items from an upgrade.md file.
+// Items are sections carrying a "Likelihood Of Impact" line; their component
+// is the nearest enclosing level 3 heading.
+func ParseUpgradeGuide(content, version string) []UpgradeItem {
+	sections := ParseSections(content)
+	lines := strings.Split(content, "\n")
+
+	var items []UpgradeItem
+	target := version
+	component := ""
+
+	for i, sec := range sections {
+		if sec.Level <= 2 {
+			component = ""
+			if m := upgradeHeadingPattern.FindStringSubmatch(sec.Title); m != nil {
+				target = m[1] + ".x"
+			}
+		}
+		if sec.Level == 3 {
+			component = sec.Title
+		}
+
+		m := likelihoodPattern.FindStringSubmatch(sec.Body)
+		if m == nil || sec.Level < 3 {
+			continue
+		}
+
+		end := sectionEnd(sections, i, true)
+		items = append(items, UpgradeItem{
+			TargetVersion: target,
+			Component:     component,
+			Title:         sec.Title,
+			Anchor:        sec.Anchor,
+			Impact:        strings.ToLower(m[1]),
+			Content:       strings.TrimSpace(strings.Join(lines[sec.StartLine+1:end], "\n")),
+		})
+	}
+
+	return items
+}
+
+// UpgradePlan holds the upgrade items between two versions
+type UpgradePlan struct {
+	FromVersion string
+	ToVersion   string
+	Filter      UpgradeFilter
+	Path        []string // versions whose guides are chained, oldest first
+	Missing     []string // versions whose guide is not available locally
+	Items       []UpgradeItem
+}
+
+// PlanUpgrade chains the upgrade guides of every version after fromVersion up
+// to toVersion and collects the items matching filter
+func (m *Manager) PlanUpgrade(ctx context.Context, fromVersion, toVersion string, filter UpgradeFilter) (*UpgradePlan, error) {
+	path, err := m.upgradePath(fromVersion, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	minRank := -1
+	if filter.MinImpact != "" {
+		rank, ok := ImpactRank(filter.MinImpact)
+		if !ok {
+			return nil, fmt.Errorf("unknown impact level: %s (use high, medium, low, very low or optional)", filter.MinImpact)
+		}
+		minRank = rank
+	}
+	componentTerms := tokenize(filter.Component)
+
+	var items []UpgradeItem
+	var missing []string
+
+	for _, version := range path {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		content, err := m.ReadDoc(ctx, version, upgradeGuideFile)
+		if err != nil {
+			missing = append(missing, version)
+			continue
+		}
+
+		for _, item := range ParseUpgradeGuide(content, version) {
+			// Guides occasionally carry notes for other releases; keep the requested hop only
+			if item.TargetVersion != version {
+				continue
+			}
+			// Items without a recognised impact cannot be shown to meet the minimum
+			if rank, ok := ImpactRank(item.Impact); minRank >= 0 && (!ok || rank < minRank) {
+				continue
+			}
+			if len(componentTerms) > 0 && !matchesAllTerms(item.Component+" "+item.Title, componentTerms) {
+				continue
+			}
+			items = append(items, item)
+		}
+	}
+
+	// Highest impact first within each hop
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].TargetVersion != items[j].TargetVersion {
+			return models.VersionMajor(items[i].TargetVersion) < models.VersionMajor(items[j].TargetVersion)
+		}
+		rankI, _ := ImpactRank(items[i].Impact)
+		rankJ, _ := ImpactRank(items[j].Impact)
+		return rankI > rankJ
+	})
+
+	return &UpgradePlan{
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Filter:      filter,
+		Path:        path,
+		Missing:     missing,
+		Items:       items,
+	}, nil
+}
+
+// FormatUpgradePlan renders an upgrade plan as Markdown, with the full text of
+// each item when details is set
+func FormatUpgradePlan(plan *UpgradePlan, details bool) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("# Laravel Upgrade Guide: %s → %s\n\n", plan.FromVersion, plan.ToVersion))
+	output.WriteString(fmt.Sprintf("**Chained guides:** %s\n", strings.Join(plan.Path, ", ")))
+	if plan.Filter.MinImpact != "" {
+		output.WriteString(fmt.Sprintf("**Minimum impact:** %s\n", strings.ToLower(plan.Filter.MinImpact)))
+	}
+	if plan.Filter.Component != "" {
+		output.WriteString(fmt.Sprintf("**Component:** %s\n", plan.Filter.Component))
+	}
+	if len(plan.Missing) > 0 {
+		output.WriteString(fmt.Sprintf("**Missing guides:** %s (run update_laravel_docs for these versions)\n", strings.Join(plan.Missing, ", ")))
+	}
+	output.WriteString("\n")
+
+	if len(plan.Items) == 0 {
+		output.WriteString("No matching upgrade items found.\n")
+		return output.String()
+	}
+
+	output.WriteString(fmt.Sprintf("Found %d upgrade items.\n\n", len(plan.Items)))
+
+	currentTarget := ""
+	for _, item := range plan.Items {
+		if item.TargetVersion != currentTarget {
+			output.WriteString(fmt.Sprintf("## Upgrading to %s\n\n", item.TargetVersion))
+			currentTarget = item.TargetVersion
+		}
+
+		heading := item.Title
+		if item.Component != "" && item.Component != item.Title {
+			heading = item.Component + ": " + item.Title
+		}
+
+		if !details {
+			output.WriteString(fmt.Sprintf("- [%s] %s (%s#%s)\n", item.Impact, heading, upgradeGuideFile, item.Anchor))
+			continue
+		}
+
+		output.WriteString(fmt.Sprintf("### [%s] %s\n\n", item.Impact, heading))
+		output.WriteString(fmt.Sprintf("**Source:** %s/%s#%s\n\n", item.TargetVersion, upgradeGuideFile, item.Anchor))
+		output.WriteString(item.Content)
+		output.WriteString("\n\n")
+	}
+
+	return output.String()
+}
+
+// upgradePath returns the versions whose upgrade guides cover fromVersion to
+// toVersion, oldest first
+func (m *Manager) upgradePath(fromVersion, toVersion string) ([]string, error) {
+	for _, version := range []string{fromVersion, toVersion} {
+		if !m.isKnownVersion(version) {
+			return nil, fmt.Errorf("unsupported version: %s", version)
+		}
+	}
+
+	from, to := models.VersionMajor(fromVersion), models.VersionMajor(toVersion)
+	if from >= to {
+		return nil, fmt.Errorf("from_version (%s) must be older than to_version (%s)", fromVersion, toVersion)
+	}
+
+	var path []string
+	for v := from + 1; v <= to; v++ {
+		version := fmt.Sprintf("%d.x", v)
+		if m.isKnownVersion(version) {
+			path = append(path, version)
+		}
+	}
+	return path, nil
+}
+
+// matchesAllTerms reports whether every term occurs in text
+func matchesAllTerms(text string, terms []string) bool {
+	have := make(map[string]bool)
+	for _, term := range tokenize(text) {
+		have[term] = true
+	}
+	for _, term := range terms {
+		if !have[term] {
+			return false
+		}
+	}
+	return true
+}
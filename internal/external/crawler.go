@@ -0,0 +1,337 @@
+package external
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/logging"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxSitemaps limits how many sitemap files, including nested ones, are read per crawl
+const maxSitemaps = 5
+
+// CrawlRules limit how much of a documentation site is crawled
+type CrawlRules struct {
+	MaxDepth int           // link hops followed from the root URL
+	MaxPages int           // pages kept per crawl
+	Delay    time.Duration // minimum time between requests to one host
+	Include  []string      // path prefixes followed; empty follows every path below the root
+	Exclude  []string      // path prefixes never followed
+}
+
+// DefaultCrawlRules keep a crawl of a documentation site small and polite
+var DefaultCrawlRules = CrawlRules{
+	MaxDepth: 2,
+	MaxPages: 50,
+	Delay:    time.Second,
+}
+
+// Page is one crawled documentation page
+type Page struct {
+	URL     string
+	Title   string
+	Content string // Markdown
+}
+
+// skippedExtensions are linked files that are not documentation pages
+var skippedExtensions = map[string]bool{
+	".css": true, ".js": true, ".json": true, ".xml": true, ".txt": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".ico": true,
+	".pdf": true, ".zip": true, ".gz": true, ".mp4": true, ".woff": true, ".woff2": true,
+}
+
+// crawl holds the state of one crawl of a documentation site
+type crawl struct {
+	scraper *WebScraper
+	rules   CrawlRules
+	root    *url.URL
+	robots  *robotsRules
+	delay   time.Duration
+	seen    map[string]bool
+}
+
+// crawlItem is a URL waiting to be fetched with its distance from the root
+type crawlItem struct {
+	url   *url.URL
+	depth int
+}
+
+// Crawl fetches the pages of a documentation site: the root URL, the pages its
+// sitemap lists and the pages reachable through links up to rules.MaxDepth hops
+// away. Only pages on the root's host below the root path are followed, and
+// robots.txt is honoured.
+func (w *WebScraper) Crawl(ctx context.Context, rootURL string, rules CrawlRules) ([]Page, error) {
+	root, err := url.Parse(rootURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if rules.MaxPages <= 0 {
+		rules.MaxPages = DefaultCrawlRules.MaxPages
+	}
+
+	c := &crawl{scraper: w, rules: rules, seen: make(map[string]bool)}
+	c.setRoot(ctx, root)
+
+	if !c.robots.allowed(requestPath(root)) {
+		return nil, fmt.Errorf("robots.txt disallows crawling %s", rootURL)
+	}
+
+	first, err := c.scraper.fetch(ctx, root.String(), c.delay)
+	if err != nil {
+		return nil, err
+	}
+	// Follow a redirect of the root, such as envoyer.io/docs to a docs subdomain
+	if first.URL.Host != root.Host || first.URL.Path != root.Path {
+		c.setRoot(ctx, first.URL)
+	}
+	c.seen[pageKey(c.root)] = true
+
+	var pages []Page
+	var queue []crawlItem
+
+	page, links := c.parse(first)
+	if page != nil {
+		pages = append(pages, *page)
+	}
+	if rules.MaxDepth > 0 {
+		queue = c.enqueue(queue, c.sitemapURLs(ctx), 1)
+		queue = c.enqueue(queue, links, 1)
+	}
+
+	for len(queue) > 0 && len(pages) < rules.MaxPages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		item := queue[0]
+		queue = queue[1:]
+
+		if !c.robots.allowed(requestPath(item.url)) {
+			continue
+		}
+
+		fetched, err := c.scraper.fetch(ctx, item.url.String(), c.delay)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			logging.Debug("Crawl skipped %s: %v", item.url, err)
+			continue
+		}
+		// A redirect may lead outside the site or to a page already crawled
+		if key := pageKey(fetched.URL); key != pageKey(item.url) {
+			if !c.inScope(fetched.URL) || c.seen[key] {
+				continue
+			}
+			c.seen[key] = true
+		}
+
+		page, links := c.parse(fetched)
+		if page != nil {
+			pages = append(pages, *page)
+		}
+		if item.depth < rules.MaxDepth {
+			queue = c.enqueue(queue, links, item.depth+1)
+		}
+	}
+
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no documentation content found at %s", rootURL)
+	}
+	return pages, nil
+}
+
+// setRoot scopes the crawl to a root URL and loads the robots.txt of its host
+func (c *crawl) setRoot(ctx context.Context, root *url.URL) {
+	hostChanged := c.root == nil || c.root.Host != root.Host
+	c.root = root
+	if !hostChanged {
+		return
+	}
+
+	c.robots = nil
+	c.delay = c.rules.Delay
+
+	robotsURL := &url.URL{Scheme: root.Scheme, Host: root.Host, Path: "/robots.txt"}
+	fetched, err := c.scraper.fetch(ctx, robotsURL.String(), c.delay)
+	if err != nil {
+		// A site without robots.txt allows everything
+		return
+	}
+	c.robots = parseRobots(fetched.Body, userAgent)
+	c.delay = max(c.delay, c.robots.crawlDelay)
+}
+
+// parse converts a fetched page and returns the links it contains. The page is
+// nil when it holds no content.
+func (c *crawl) parse(fetched *fetchedPage) (*Page, []*url.URL) {
+	if !fetched.isHTML() {
+		content := strings.TrimSpace(fetched.Body)
+		if content == "" {
+			return nil, nil
+		}
+		return &Page{URL: fetched.URL.String(), Title: path.Base(fetched.URL.Path), Content: content}, nil
+	}
+
+	doc, err := html.Parse(strings.NewReader(fetched.Body))
+	if err != nil {
+		return nil, nil
+	}
+
+	links := pageLinks(doc, fetched.URL)
+	content := documentMarkdown(doc, fetched.URL)
+	if content == "" {
+		return nil, links
+	}
+	return &Page{URL: fetched.URL.String(), Title: markdownTitle(content), Content: content}, links
+}
+
+// enqueue adds the in-scope URLs not seen before to the queue
+func (c *crawl) enqueue(queue []crawlItem, urls []*url.URL, depth int) []crawlItem {
+	for _, u := range urls {
+		key := pageKey(u)
+		if c.seen[key] || !c.inScope(u) || skippedExtensions[strings.ToLower(path.Ext(u.Path))] {
+			continue
+		}
+		c.seen[key] = true
+		queue = append(queue, crawlItem{url: u, depth: depth})
+	}
+	return queue
+}
+
+// inScope reports whether a URL is on the root's host below the root path and
+// passes the include and exclude prefixes of the rules
+func (c *crawl) inScope(u *url.URL) bool {
+	if u.Scheme != c.root.Scheme || u.Host != c.root.Host || !underPath(u.Path, c.root.Path) {
+		return false
+	}
+
+	for _, prefix := range c.rules.Exclude {
+		if underPath(u.Path, prefix) {
+			return false
+		}
+	}
+	if len(c.rules.Include) == 0 {
+		return true
+	}
+	for _, prefix := range c.rules.Include {
+		if underPath(u.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// underPath reports whether p is scope or below it. A scope ending in a slash
+// matches everything starting with it.
+func underPath(p, scope string) bool {
+	if scope == "" || scope == "/" {
+		return true
+	}
+	if strings.HasSuffix(scope, "/") {
+		return strings.HasPrefix(p, scope)
+	}
+	return p == scope || strings.HasPrefix(p, scope+"/")
+}
+
+// sitemapURLs returns the page URLs listed by the site's sitemaps, following
+// sitemap indexes one level deep
+func (c *crawl) sitemapURLs(ctx context.Context) []*url.URL {
+	var pending []string
+	if c.robots != nil {
+		pending = append(pending, c.robots.sitemaps...)
+	}
+	if len(pending) == 0 {
+		pending = []string{(&url.URL{Scheme: c.root.Scheme, Host: c.root.Host, Path: "/sitemap.xml"}).String()}
+	}
+
+	var urls []*url.URL
+	for read := 0; len(pending) > 0 && read < maxSitemaps; read++ {
+		sitemapURL := pending[0]
+		pending = pending[1:]
+
+		fetched, err := c.scraper.fetch(ctx, sitemapURL, c.delay)
+		if err != nil {
+			continue
+		}
+
+		var sitemap struct {
+			URLs     []string `xml:"url>loc"`
+			Sitemaps []string `xml:"sitemap>loc"`
+		}
+		if err := xml.Unmarshal([]byte(fetched.Body), &sitemap); err != nil {
+			continue
+		}
+
+		pending = append(pending, sitemap.Sitemaps...)
+		for _, loc := range sitemap.URLs {
+			if u, err := url.Parse(strings.TrimSpace(loc)); err == nil {
+				u.Fragment = ""
+				urls = append(urls, u)
+			}
+		}
+	}
+	return urls
+}
+
+// pageLinks returns the targets of every link in a document, navigation included,
+// resolved against base and without fragments
+func pageLinks(doc *html.Node, base *url.URL) []*url.URL {
+	var links []*url.URL
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			if href := strings.TrimSpace(attr(n, "href")); href != "" && !strings.HasPrefix(href, "#") {
+				if ref, err := url.Parse(href); err == nil {
+					u := base.ResolveReference(ref)
+					u.Fragment = ""
+					links = append(links, u)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+	return links
+}
+
+// markdownTitle returns the text of the first level one heading of a Markdown page
+func markdownTitle(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if title, ok := strings.CutPrefix(line, "# "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
+
+// requestPath returns the path and query robots.txt rules are matched against
+func requestPath(u *url.URL) string {
+	p := u.EscapedPath()
+	if p == "" {
+		p = "/"
+	}
+	if u.RawQuery != "" {
+		p += "?" + u.RawQuery
+	}
+	return p
+}
+
+// pageKey identifies a page regardless of a trailing slash
+func pageKey(u *url.URL) string {
+	key := *u
+	key.Fragment = ""
+	if len(key.Path) > 1 {
+		key.Path = strings.TrimSuffix(key.Path, "/")
+	}
+	return key.String()
+}
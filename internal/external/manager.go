@@ -1,62 +1,40 @@
 package external
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
 
 const (
 	cacheValidDuration = 24 * time.Hour // Cache valid for 24 hours
+	maxServiceContexts = 10             // Matches shown per service by SearchServicesWithContext
 )
 
-// ServiceConfig holds configuration for an external Laravel service
-type ServiceConfig struct {
-	Name        string
-	URL         string
-	Description string
-}
-
 // ServiceMetadata holds cache metadata for a service
 type ServiceMetadata struct {
 	ServiceName string    `json:"service_name"`
 	URL         string    `json:"url"`
 	CachedAt    time.Time `json:"cached_at"`
 	ContentSize int       `json:"content_size"`
+	Pages       int       `json:"pages,omitempty"`
 }
 
+// ProgressFunc receives the progress of an update: done of total services and a
+// short description
+type ProgressFunc func(done, total int, message string)
+
 // ExternalManager manages external Laravel service documentation
 type ExternalManager struct {
-	scraper   *WebScraper
-	cachePath string
-	services  map[string]ServiceConfig
-}
-
-// Service URL mappings for external Laravel services
-var serviceURLs = map[string]ServiceConfig{
-	"forge": {
-		Name:        "Laravel Forge",
-		URL:         "https://forge.laravel.com/docs",
-		Description: "Server management and deployment platform",
-	},
-	"vapor": {
-		Name:        "Laravel Vapor",
-		URL:         "https://docs.vapor.build",
-		Description: "Serverless deployment platform",
-	},
-	"envoyer": {
-		Name:        "Laravel Envoyer",
-		URL:         "https://envoyer.io/docs",
-		Description: "Zero-downtime deployment platform",
-	},
-	"nova": {
-		Name:        "Laravel Nova",
-		URL:         "https://nova.laravel.com/docs",
-		Description: "Administration panel for Laravel",
-	},
+	scraper    *WebScraper
+	cachePath  string
+	services   []ServiceConfig
+	crawlRules CrawlRules
 }
 
 // NewExternalManager creates a new external documentation manager
@@ -68,18 +46,54 @@ func NewExternalManager(cachePath string) *ExternalManager {
 	}
 
 	return &ExternalManager{
-		scraper:   NewWebScraper(),
-		cachePath: cachePath,
-		services:  serviceURLs,
+		scraper:    NewWebScraper(),
+		cachePath:  cachePath,
+		services:   DefaultServices,
+		crawlRules: DefaultCrawlRules,
 	}
 }
 
+// SetCrawlRules sets how much of each service's documentation site is crawled,
+// unless the service overrides it
+func (m *ExternalManager) SetCrawlRules(rules CrawlRules) {
+	m.crawlRules = rules
+}
+
+// SetServices replaces the known services, such as with ones loaded by LoadServices
+func (m *ExternalManager) SetServices(services []ServiceConfig) {
+	m.services = services
+}
+
+// Services returns the known services in registry order
+func (m *ExternalManager) Services() []ServiceConfig {
+	return m.services
+}
+
+// Service looks up a service by ID
+func (m *ExternalManager) Service(serviceName string) (ServiceConfig, bool) {
+	for _, config := range m.services {
+		if config.ID == serviceName {
+			return config, true
+		}
+	}
+	return ServiceConfig{}, false
+}
+
+// ServiceNames returns the IDs of the known services in registry order
+func (m *ExternalManager) ServiceNames() []string {
+	names := make([]string, len(m.services))
+	for i, config := range m.services {
+		names[i] = config.ID
+	}
+	return names
+}
+
 // UpdateService updates documentation for a specific service
-func (m *ExternalManager) UpdateService(serviceName string, force bool) (string, error) {
+func (m *ExternalManager) UpdateService(ctx context.Context, serviceName string, force bool) (string, error) {
 	// Validate service name
-	config, exists := m.services[serviceName]
-	if !exists {
-		return "", fmt.Errorf("unknown service: %s. Available services: forge, vapor, envoyer, nova", serviceName)
+	config, err := m.service(serviceName)
+	if err != nil {
+		return "", err
 	}
 
 	// Check if cache exists and is valid (unless forced)
@@ -89,50 +103,76 @@ func (m *ExternalManager) UpdateService(serviceName string, force bool) (string,
 		}
 	}
 
-	// Fetch content from service URL
-	content, err := m.scraper.FetchResource(config.URL)
+	// Crawl the service's documentation site
+	pages, err := m.scraper.Crawl(ctx, config.URL, config.Crawl.apply(m.crawlRules))
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch %s documentation: %w", config.Name, err)
 	}
 
 	// Save to cache
-	if err := m.saveToCache(serviceName, content); err != nil {
+	if err := m.savePages(serviceName, pages); err != nil {
 		return "", fmt.Errorf("failed to cache %s documentation: %w", config.Name, err)
 	}
 
+	contentSize := 0
+	for _, page := range pages {
+		contentSize += len(page.Content)
+	}
+
 	// Save metadata
 	metadata := ServiceMetadata{
 		ServiceName: serviceName,
 		URL:         config.URL,
 		CachedAt:    time.Now(),
-		ContentSize: len(content),
+		ContentSize: contentSize,
+		Pages:       len(pages),
 	}
 	if err := m.saveMetadata(serviceName, metadata); err != nil {
 		// Non-fatal, just log
 		fmt.Fprintf(os.Stderr, "Warning: failed to save metadata for %s: %v\n", serviceName, err)
 	}
 
-	return fmt.Sprintf("Successfully updated %s documentation\n- URL: %s\n- Content Size: %d bytes\n- Cached at: %s",
-		config.Name, config.URL, len(content), time.Now().Format(time.RFC3339)), nil
+	return fmt.Sprintf("Successfully updated %s documentation\n- URL: %s\n- Pages: %d\n- Content Size: %d bytes\n- Cached at: %s",
+		config.Name, config.URL, len(pages), contentSize, time.Now().Format(time.RFC3339)), nil
+}
+
+// service looks up a service by ID, failing with the available IDs when it is unknown
+func (m *ExternalManager) service(serviceName string) (ServiceConfig, error) {
+	config, exists := m.Service(serviceName)
+	if !exists {
+		return ServiceConfig{}, fmt.Errorf("unknown service: %s. Available services: %s", serviceName, strings.Join(m.ServiceNames(), ", "))
+	}
+	return config, nil
 }
 
-// UpdateServices updates documentation for multiple services
-func (m *ExternalManager) UpdateServices(serviceNames []string, force bool) (string, error) {
+// UpdateServices updates documentation for multiple services. progress, which may
+// be nil, is called after every service. Cancelling ctx skips the remaining services.
+func (m *ExternalManager) UpdateServices(ctx context.Context, serviceNames []string, force bool, progress ProgressFunc) (string, error) {
 	// If no services specified, update all
 	if len(serviceNames) == 0 {
-		serviceNames = []string{"forge", "vapor", "envoyer", "nova"}
+		serviceNames = m.ServiceNames()
 	}
 
 	var results []string
 	var errors []string
 
-	for _, serviceName := range serviceNames {
-		_, err := m.UpdateService(serviceName, force)
+	for i, serviceName := range serviceNames {
+		if err := ctx.Err(); err != nil {
+			return "", fmt.Errorf("update cancelled after %d of %d services: %w", i, len(serviceNames), err)
+		}
+
+		_, err := m.UpdateService(ctx, serviceName, force)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("- %s: %v", serviceName, err))
 		} else {
 			results = append(results, fmt.Sprintf("✓ %s", serviceName))
 		}
+		if progress != nil {
+			progress(i+1, len(serviceNames), serviceName)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("update cancelled: %w", err)
 	}
 
 	// Build response
@@ -164,62 +204,93 @@ func (m *ExternalManager) UpdateServices(serviceNames []string, force bool) (str
 	return response.String(), nil
 }
 
-// SearchServices searches through cached external service documentation
-func (m *ExternalManager) SearchServices(query string, serviceNames []string) (string, error) {
+// ServiceMatch holds how often a query occurs in a service's cached documentation
+type ServiceMatch struct {
+	Service string
+	Name    string
+	Matches int
+}
+
+// MatchServices counts query matches in each cached service, skipping services without matches
+func (m *ExternalManager) MatchServices(ctx context.Context, query string, serviceNames []string) ([]ServiceMatch, error) {
 	// If no services specified, search all
 	if len(serviceNames) == 0 {
-		serviceNames = []string{"forge", "vapor", "envoyer", "nova"}
+		serviceNames = m.ServiceNames()
 	}
 
 	query = strings.ToLower(query)
-	var results []string
-	totalMatches := 0
+	var matches []ServiceMatch
 
 	for _, serviceName := range serviceNames {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		// Validate service
-		config, exists := m.services[serviceName]
+		config, exists := m.Service(serviceName)
 		if !exists {
 			continue
 		}
 
-		// Get cached content
-		content, err := m.getCachedContent(serviceName)
+		// Get cached pages
+		pages, err := m.cachedPages(serviceName)
 		if err != nil {
 			// Service not cached, skip
 			continue
 		}
 
-		// Search for query in content
-		matches := m.searchInContent(content, query)
-		if matches > 0 {
-			results = append(results, fmt.Sprintf("**%s:** %d matches", config.Name, matches))
-			totalMatches += matches
+		// Search for query in every page
+		count := 0
+		for _, page := range pages {
+			count += m.searchInContent(page.Content, query)
+		}
+		if count > 0 {
+			matches = append(matches, ServiceMatch{Service: serviceName, Name: config.Name, Matches: count})
 		}
 	}
 
+	return matches, nil
+}
+
+// SearchServices searches through cached external service documentation
+func (m *ExternalManager) SearchServices(ctx context.Context, query string, serviceNames []string) (string, error) {
+	matches, err := m.MatchServices(ctx, query, serviceNames)
+	if err != nil {
+		return "", err
+	}
+	return FormatServiceMatches(query, matches), nil
+}
+
+// FormatServiceMatches renders MatchServices results as Markdown
+func FormatServiceMatches(query string, matches []ServiceMatch) string {
 	// Build response
 	var response strings.Builder
-	response.WriteString(fmt.Sprintf("# Search Results for '%s'\n\n", query))
+	response.WriteString(fmt.Sprintf("# Search Results for '%s'\n\n", strings.ToLower(query)))
 
-	if len(results) == 0 {
+	if len(matches) == 0 {
 		response.WriteString("No matches found in cached external service documentation.\n\n")
 		response.WriteString("**Tip:** Try updating the service documentation first using `update_external_laravel_docs`.\n")
-		return response.String(), nil
+		return response.String()
 	}
 
-	response.WriteString(fmt.Sprintf("Found **%d total matches** across %d services:\n\n", totalMatches, len(results)))
-	for _, result := range results {
-		response.WriteString("- " + result + "\n")
+	totalMatches := 0
+	for _, match := range matches {
+		totalMatches += match.Matches
 	}
 
-	return response.String(), nil
+	response.WriteString(fmt.Sprintf("Found **%d total matches** across %d services:\n\n", totalMatches, len(matches)))
+	for _, match := range matches {
+		response.WriteString(fmt.Sprintf("- **%s:** %d matches\n", match.Name, match.Matches))
+	}
+
+	return response.String()
 }
 
 // SearchServicesWithContext searches and returns matching text with context
-func (m *ExternalManager) SearchServicesWithContext(query string, serviceNames []string, contextLength int) (string, error) {
+func (m *ExternalManager) SearchServicesWithContext(ctx context.Context, query string, serviceNames []string, contextLength int) (string, error) {
 	// If no services specified, search all
 	if len(serviceNames) == 0 {
-		serviceNames = []string{"forge", "vapor", "envoyer", "nova"}
+		serviceNames = m.ServiceNames()
 	}
 
 	if contextLength <= 0 {
@@ -231,21 +302,38 @@ func (m *ExternalManager) SearchServicesWithContext(query string, serviceNames [
 	totalMatches := 0
 
 	for _, serviceName := range serviceNames {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
 		// Validate service
-		config, exists := m.services[serviceName]
+		config, exists := m.Service(serviceName)
 		if !exists {
 			continue
 		}
 
-		// Get cached content
-		content, err := m.getCachedContent(serviceName)
+		// Get cached pages
+		pages, err := m.cachedPages(serviceName)
 		if err != nil {
 			// Service not cached, skip
 			continue
 		}
 
-		// Find matches with context
-		contexts := m.findContexts(content, query, contextLength)
+		// Find matches with context, labelled with the page they are on
+		var contexts []string
+		for _, page := range pages {
+			source := fmt.Sprintf("**Page:** [%s](%s)", page.Title, page.URL)
+			if page.ID != "" {
+				source += fmt.Sprintf(" (`%s`)", page.ID)
+			}
+			for _, match := range m.findContexts(page.Content, query, contextLength) {
+				contexts = append(contexts, source+"\n\n"+match)
+			}
+			if len(contexts) >= maxServiceContexts {
+				contexts = contexts[:maxServiceContexts]
+				break
+			}
+		}
 		if len(contexts) > 0 {
 			results = append(results, fmt.Sprintf("\n## %s (%d matches)\n\n%s",
 				config.Name, len(contexts), strings.Join(contexts, "\n\n---\n\n")))
@@ -263,7 +351,7 @@ func (m *ExternalManager) SearchServicesWithContext(query string, serviceNames [
 		return response.String(), nil
 	}
 
-	response.WriteString(fmt.Sprintf("Found **%d total matches** across services:\n", totalMatches))
+	response.WriteString(fmt.Sprintf("Found **%d total matches** across services. Read a whole page with `read_external_laravel_doc`.\n", totalMatches))
 	for _, result := range results {
 		response.WriteString(result)
 	}
@@ -275,15 +363,30 @@ func (m *ExternalManager) SearchServicesWithContext(query string, serviceNames [
 func (m *ExternalManager) GetCachedServices() []string {
 	var cached []string
 
-	for serviceName := range m.services {
-		if valid, _ := m.isCacheValid(serviceName); valid {
-			cached = append(cached, serviceName)
+	for _, config := range m.services {
+		if valid, _ := m.isCacheValid(config.ID); valid {
+			cached = append(cached, config.ID)
 		}
 	}
 
 	return cached
 }
 
+// StaleServices returns the services whose cached documentation has expired, sorted by name
+func (m *ExternalManager) StaleServices() []string {
+	var stale []string
+
+	for _, config := range m.services {
+		// A missing or unreadable cache is reported as an error, an expired one is not
+		if valid, err := m.isCacheValid(config.ID); !valid && err == nil && m.cachePath != "" {
+			stale = append(stale, config.ID)
+		}
+	}
+
+	sort.Strings(stale)
+	return stale
+}
+
 // --- Private helper methods ---
 
 // isCacheValid checks if cache exists and is still valid
@@ -292,30 +395,31 @@ func (m *ExternalManager) isCacheValid(serviceName string) (bool, error) {
 		return false, nil
 	}
 
-	metadataPath := filepath.Join(m.cachePath, fmt.Sprintf("%s_metadata.json", serviceName))
-	data, err := os.ReadFile(metadataPath)
+	metadata, err := m.loadMetadata(serviceName)
 	if err != nil {
 		return false, err
 	}
 
-	var metadata ServiceMetadata
-	if err := json.Unmarshal(data, &metadata); err != nil {
-		return false, err
-	}
-
 	// Check if cache is still valid
 	age := time.Since(metadata.CachedAt)
 	return age < cacheValidDuration, nil
 }
 
-// saveToCache saves content to cache file
-func (m *ExternalManager) saveToCache(serviceName, content string) error {
+// loadMetadata reads the cache metadata of a service
+func (m *ExternalManager) loadMetadata(serviceName string) (ServiceMetadata, error) {
+	var metadata ServiceMetadata
 	if m.cachePath == "" {
-		return fmt.Errorf("cache path not configured")
+		return metadata, fmt.Errorf("cache path not configured")
 	}
 
-	cachePath := filepath.Join(m.cachePath, fmt.Sprintf("%s_docs.txt", serviceName))
-	return os.WriteFile(cachePath, []byte(content), 0644)
+	metadataPath := filepath.Join(m.cachePath, fmt.Sprintf("%s_metadata.json", serviceName))
+	data, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return metadata, err
+	}
+
+	err = json.Unmarshal(data, &metadata)
+	return metadata, err
 }
 
 // saveMetadata saves service metadata
@@ -333,21 +437,6 @@ func (m *ExternalManager) saveMetadata(serviceName string, metadata ServiceMetad
 	return os.WriteFile(metadataPath, data, 0644)
 }
 
-// getCachedContent reads cached content for a service
-func (m *ExternalManager) getCachedContent(serviceName string) (string, error) {
-	if m.cachePath == "" {
-		return "", fmt.Errorf("cache path not configured")
-	}
-
-	cachePath := filepath.Join(m.cachePath, fmt.Sprintf("%s_docs.txt", serviceName))
-	data, err := os.ReadFile(cachePath)
-	if err != nil {
-		return "", fmt.Errorf("service documentation not cached: %w", err)
-	}
-
-	return string(data), nil
-}
-
 // searchInContent counts matches of query in content
 func (m *ExternalManager) searchInContent(content, query string) int {
 	contentLower := strings.ToLower(content)
@@ -0,0 +1,503 @@
+package external
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// minMainContentLength is the text length below which a candidate main
+// container is assumed to be a teaser rather than the page content
+const minMainContentLength = 200
+
+// docContainerClasses are classes documentation site generators put on the
+// element holding the page content
+var docContainerClasses = []string{
+	"markdown-body",      // GitHub style renderers
+	"theme-doc-markdown", // Docusaurus
+	"vp-doc",             // VitePress
+	"md-content",         // MkDocs Material
+	"docs-content",
+	"doc-content",
+	"documentation",
+	"prose", // Tailwind Typography
+}
+
+// skippedTags never contribute content
+var skippedTags = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Nav: true, atom.Footer: true, atom.Aside: true,
+	atom.Form: true, atom.Button: true, atom.Input: true, atom.Select: true,
+	atom.Textarea: true, atom.Svg: true, atom.Iframe: true, atom.Canvas: true,
+}
+
+// blockTags start a new block when they appear in running text
+var blockTags = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Blockquote: true, atom.Dd: true,
+	atom.Details: true, atom.Div: true, atom.Dl: true, atom.Dt: true,
+	atom.Fieldset: true, atom.Figcaption: true, atom.Figure: true, atom.H1: true,
+	atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Header: true, atom.Hr: true, atom.Li: true, atom.Main: true, atom.Ol: true,
+	atom.P: true, atom.Pre: true, atom.Section: true, atom.Summary: true,
+	atom.Table: true, atom.Ul: true,
+}
+
+var (
+	spacePattern     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLinePattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToMarkdown extracts the main content of an HTML page as Markdown. Links
+// are resolved against base when it is set.
+func htmlToMarkdown(page string, base *url.URL) string {
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return ""
+	}
+	return documentMarkdown(doc, base)
+}
+
+// documentMarkdown converts the main content of a parsed HTML document to Markdown
+func documentMarkdown(doc *html.Node, base *url.URL) string {
+	c := &converter{base: base}
+	markdown := strings.Join(c.blocks(mainContent(doc)), "\n\n")
+
+	// Pages whose content starts below the title still get one
+	if !strings.HasPrefix(markdown, "# ") {
+		if title := findElement(doc, func(n *html.Node) bool { return n.DataAtom == atom.Title }); title != nil {
+			if text := strings.TrimSpace(spacePattern.ReplaceAllString(textContent(title), " ")); text != "" {
+				markdown = "# " + text + "\n\n" + markdown
+			}
+		}
+	}
+
+	return strings.TrimSpace(blankLinePattern.ReplaceAllString(markdown, "\n\n"))
+}
+
+// mainContent returns the element holding the page content: the first
+// article, documentation container or main element with enough text, and
+// the body otherwise
+func mainContent(doc *html.Node) *html.Node {
+	candidates := []func(n *html.Node) bool{
+		func(n *html.Node) bool { return n.DataAtom == atom.Article },
+		func(n *html.Node) bool {
+			for _, class := range docContainerClasses {
+				if hasClass(n, class) {
+					return true
+				}
+			}
+			return false
+		},
+		func(n *html.Node) bool { return n.DataAtom == atom.Main || attr(n, "role") == "main" },
+	}
+
+	for _, match := range candidates {
+		if n := findElement(doc, match); n != nil && len(strings.TrimSpace(textContent(n))) >= minMainContentLength {
+			return n
+		}
+	}
+
+	if body := findElement(doc, func(n *html.Node) bool { return n.DataAtom == atom.Body }); body != nil {
+		return body
+	}
+	return doc
+}
+
+// converter renders HTML nodes as Markdown
+type converter struct {
+	base *url.URL
+}
+
+// blocks renders the children of n as Markdown blocks, gathering runs of
+// inline content into paragraphs
+func (c *converter) blocks(n *html.Node) []string {
+	var blocks []string
+	var run strings.Builder
+
+	flush := func() {
+		if text := cleanInline(run.String()); text != "" {
+			blocks = append(blocks, text)
+		}
+		run.Reset()
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && blockTags[child.DataAtom] {
+			flush()
+			blocks = append(blocks, c.block(child)...)
+			continue
+		}
+		run.WriteString(c.inline(child))
+	}
+	flush()
+
+	return blocks
+}
+
+// block renders a block-level element
+func (c *converter) block(n *html.Node) []string {
+	if skipped(n) {
+		return nil
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := cleanInline(c.children(n))
+		if text == "" {
+			return nil
+		}
+		level := int(n.Data[1] - '0')
+		return []string{strings.Repeat("#", level) + " " + text}
+
+	case atom.P, atom.Dt, atom.Summary, atom.Figcaption:
+		if text := cleanInline(c.children(n)); text != "" {
+			return []string{text}
+		}
+		return nil
+
+	case atom.Pre:
+		return []string{codeBlock(n)}
+
+	case atom.Ul, atom.Ol:
+		if list := c.list(n); list != "" {
+			return []string{list}
+		}
+		return nil
+
+	case atom.Table:
+		if table := c.table(n); table != "" {
+			return []string{table}
+		}
+		return nil
+
+	case atom.Blockquote:
+		inner := strings.Join(c.blocks(n), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		lines := strings.Split(inner, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return []string{strings.Join(lines, "\n")}
+
+	case atom.Hr:
+		return []string{"---"}
+	}
+
+	return c.blocks(n)
+}
+
+// inline renders a node inside running text
+func (c *converter) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return spacePattern.ReplaceAllString(n.Data, " ")
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	if skipped(n) {
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "\n"
+	case atom.Code, atom.Kbd, atom.Samp:
+		return inlineCode(spacePattern.ReplaceAllString(textContent(n), " "))
+	case atom.Strong, atom.B:
+		return wrapInline(c.children(n), "**")
+	case atom.Em, atom.I:
+		return wrapInline(c.children(n), "_")
+	case atom.A:
+		return c.link(n)
+	case atom.Img:
+		return attr(n, "alt")
+	}
+
+	if blockTags[n.DataAtom] {
+		// A block inside inline content, such as a div in a link, reads as a word break
+		return " " + c.children(n) + " "
+	}
+	return c.children(n)
+}
+
+// children renders the children of n as running text
+func (c *converter) children(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(c.inline(child))
+	}
+	return b.String()
+}
+
+// link renders an anchor, dropping targets that only make sense on the page itself
+func (c *converter) link(n *html.Node) string {
+	text := cleanInline(c.children(n))
+	href := strings.TrimSpace(attr(n, "href"))
+	if text == "" || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return text
+	}
+
+	if c.base != nil {
+		if ref, err := url.Parse(href); err == nil {
+			href = c.base.ResolveReference(ref).String()
+		}
+	}
+	return fmt.Sprintf("[%s](%s)", text, href)
+}
+
+// list renders an ordered or unordered list, indenting nested content under its item
+func (c *converter) list(n *html.Node) string {
+	number := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		number = start
+	}
+
+	var items []string
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+
+		// Items stay tight so nested lists read as part of their item
+		content := strings.Join(c.blocks(li), "\n")
+		if content == "" {
+			continue
+		}
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.ReplaceAll(content, "\n", "\n"+indent))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+// table renders a table as a Markdown pipe table, using the first row as header
+func (c *converter) table(n *html.Node) string {
+	var rows [][]string
+	width := 0
+
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode || skipped(child) {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Tr:
+				var row []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Th || cell.DataAtom == atom.Td) {
+						text := strings.ReplaceAll(cleanInline(c.children(cell)), "\n", " ")
+						row = append(row, strings.ReplaceAll(text, "|", `\|`))
+					}
+				}
+				if len(row) > 0 {
+					rows = append(rows, row)
+					width = max(width, len(row))
+				}
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				collect(child)
+			}
+		}
+	}
+	collect(n)
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// codeBlock renders a pre element as a fenced code block
+func codeBlock(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.Type == html.ElementNode && skipped(n):
+		case n.Type == html.ElementNode && n.DataAtom == atom.Br:
+			b.WriteString("\n")
+		default:
+			for child := n.FirstChild; child != nil; child = child.NextSibling {
+				walk(child)
+			}
+			// Highlighters such as Torchlight wrap every line in a div
+			if n.Type == html.ElementNode && blockTags[n.DataAtom] && !strings.HasSuffix(b.String(), "\n") {
+				b.WriteString("\n")
+			}
+		}
+	}
+	walk(n)
+
+	code := strings.Trim(b.String(), "\n")
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + codeLanguage(n) + "\n" + code + "\n" + fence
+}
+
+// codeLanguage returns the language a pre element or its code child is marked with
+func codeLanguage(pre *html.Node) string {
+	nodes := []*html.Node{pre}
+	if code := findElement(pre, func(n *html.Node) bool { return n.DataAtom == atom.Code }); code != nil {
+		nodes = append(nodes, code)
+	}
+
+	for _, n := range nodes {
+		for _, key := range []string{"data-language", "data-lang"} {
+			if lang := attr(n, key); lang != "" {
+				return lang
+			}
+		}
+		for _, class := range strings.Fields(attr(n, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if lang, ok := strings.CutPrefix(class, prefix); ok && lang != "" {
+					return lang
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// skipped reports whether an element is page furniture rather than content
+func skipped(n *html.Node) bool {
+	if skippedTags[n.DataAtom] || attr(n, "aria-hidden") == "true" || hasAttr(n, "hidden") {
+		return true
+	}
+	switch attr(n, "role") {
+	case "navigation", "banner", "contentinfo", "search":
+		return true
+	}
+	// Site headers hold navigation; article headers hold the title
+	if n.DataAtom == atom.Header {
+		return findElement(n, func(n *html.Node) bool { return n.DataAtom == atom.Nav }) != nil
+	}
+	return false
+}
+
+// cleanInline trims running text and collapses the spaces left around elements
+func cleanInline(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spacePattern.ReplaceAllString(line, " "))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// wrapInline surrounds text with a Markdown emphasis marker, keeping the
+// surrounding spaces outside of it
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := strings.Index(text, trimmed)
+	return text[:start] + marker + trimmed + marker + text[start+len(trimmed):]
+}
+
+// inlineCode wraps text in enough backticks to hold any it contains
+func inlineCode(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	fence := "`"
+	for strings.Contains(trimmed, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(trimmed, "`") || strings.HasSuffix(trimmed, "`") {
+		trimmed = " " + trimmed + " "
+	}
+	return fence + trimmed + fence
+}
+
+// textContent returns all text below n, without skipped elements
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	if n.Type == html.ElementNode && skipped(n) {
+		return ""
+	}
+
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+// findElement returns the first element below n, in document order, that matches
+func findElement(n *html.Node, match func(*html.Node) bool) *html.Node {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode {
+			continue
+		}
+		if match(child) {
+			return child
+		}
+		if found := findElement(child, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// attr returns the value of an attribute, or "" when it is not set
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasAttr reports whether an attribute is set
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// hasClass reports whether an element has a class
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
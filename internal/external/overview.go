@@ -0,0 +1,153 @@
+package external
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/docs"
+)
+
+const (
+	// maxFeatures limits the features extracted from a service's documentation
+	maxFeatures = 12
+
+	// minIntroLength is the length below which a paragraph is too short to introduce a service
+	minIntroLength = 40
+
+	// maxIntroLength truncates long introductions
+	maxIntroLength = 600
+)
+
+// genericHeadings name parts of a documentation page rather than what a service does
+var genericHeadings = map[string]bool{
+	"introduction": true, "overview": true, "getting started": true, "installation": true,
+	"requirements": true, "prerequisites": true, "next steps": true, "table of contents": true,
+	"on this page": true, "faq": true, "frequently asked questions": true, "troubleshooting": true,
+	"support": true, "summary": true, "conclusion": true, "learn more": true, "see also": true,
+	"further reading": true, "upgrading": true, "contents": true,
+}
+
+// markdownLinkPattern matches a Markdown link, capturing its text
+var markdownLinkPattern = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+
+// ServiceOverview summarizes the cached documentation of a service
+type ServiceOverview struct {
+	Metadata ServiceMetadata
+	Intro    string   // first paragraph of the root page
+	Sections []string // titles of the documentation pages in crawl order
+	Features []string // headings naming what the service offers
+}
+
+// Overview summarizes the cached documentation of a service, failing when
+// nothing is cached for it
+func (m *ExternalManager) Overview(ctx context.Context, serviceName string) (*ServiceOverview, error) {
+	config, err := m.service(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := m.cachedPages(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Caches written before metadata tracked pages still describe the crawl
+	metadata, _ := m.loadMetadata(serviceName)
+	metadata.ServiceName = serviceName
+	if metadata.URL == "" {
+		metadata.URL = config.URL
+	}
+	if metadata.Pages == 0 {
+		metadata.Pages = len(pages)
+	}
+
+	overview := &ServiceOverview{Metadata: metadata}
+	for i, page := range pages {
+		// The root page introduces the sections below it
+		if i == 0 && len(pages) > 1 {
+			continue
+		}
+		overview.Sections = append(overview.Sections, page.Title)
+	}
+	if len(pages) > 0 {
+		overview.Intro = introParagraph(pages[0].Content)
+	}
+	overview.Features = extractFeatures(pages)
+
+	return overview, nil
+}
+
+// extractFeatures returns the distinct second-level headings of the pages,
+// taking one from each page in turn so every part of the docs is represented
+func extractFeatures(pages []cachedPage) []string {
+	headings := make([][]string, len(pages))
+	for i, page := range pages {
+		for _, sec := range docs.ParseSections(page.Content) {
+			if sec.Level == 2 && !genericHeadings[strings.ToLower(sec.Title)] {
+				headings[i] = append(headings[i], sec.Title)
+			}
+		}
+	}
+
+	var features []string
+	seen := make(map[string]bool)
+	for round := 0; len(features) < maxFeatures; round++ {
+		added := false
+		for _, titles := range headings {
+			if round >= len(titles) || len(features) == maxFeatures {
+				continue
+			}
+			added = true
+			if key := strings.ToLower(titles[round]); !seen[key] {
+				seen[key] = true
+				features = append(features, titles[round])
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	return features
+}
+
+// introParagraph returns the first prose paragraph of a page, without link targets
+func introParagraph(content string) string {
+	inFence := false
+	for _, block := range strings.Split(content, "\n\n") {
+		block = strings.TrimSpace(block)
+		if inFence || strings.HasPrefix(block, "```") {
+			// A fence with blank lines inside spans several blocks
+			if strings.Count(block, "```")%2 == 1 {
+				inFence = !inFence
+			}
+			continue
+		}
+		if block == "" {
+			continue
+		}
+
+		switch block[0] {
+		case '#', '-', '*', '|', '>', '<', '!':
+			continue
+		}
+		if block[0] >= '0' && block[0] <= '9' && strings.Contains(block[:min(len(block), 4)], ".") {
+			// Ordered list
+			continue
+		}
+
+		text := strings.Join(strings.Fields(markdownLinkPattern.ReplaceAllString(block, "$1")), " ")
+		if len(text) < minIntroLength {
+			continue
+		}
+		if len(text) > maxIntroLength {
+			cut := strings.LastIndex(text[:maxIntroLength], " ")
+			if cut <= 0 {
+				cut = maxIntroLength
+			}
+			text = text[:cut] + "..."
+		}
+		return text
+	}
+	return ""
+}
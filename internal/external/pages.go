@@ -0,0 +1,298 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/docs"
+)
+
+// indexFile lists the cached pages of a service in crawl order
+const indexFile = "index.json"
+
+// PageSection is a heading of a cached page
+type PageSection struct {
+	Title  string `json:"title"`
+	Level  int    `json:"level"`
+	Anchor string `json:"anchor"`
+}
+
+// PageInfo describes a cached documentation page
+type PageInfo struct {
+	ID       string        `json:"id"` // file name without extension
+	Title    string        `json:"title"`
+	URL      string        `json:"url"`
+	File     string        `json:"file"`
+	Size     int           `json:"size"`
+	Sections []PageSection `json:"sections,omitempty"`
+}
+
+// PageContent is a cached page, or one section of it
+type PageContent struct {
+	Service string
+	Page    PageInfo
+	Section *docs.SectionContent // nil when the whole page was read
+	Content string
+}
+
+// cachedPage is a page read back from the cache
+type cachedPage struct {
+	PageInfo
+	Content string
+}
+
+// ListPages returns the cached pages of a service in crawl order
+func (m *ExternalManager) ListPages(ctx context.Context, serviceName string) ([]PageInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := m.service(serviceName); err != nil {
+		return nil, err
+	}
+	return m.loadIndex(serviceName)
+}
+
+// ReadPage returns a cached page of a service, or only the section ref points
+// at when it is set. The page may be referenced by its ID, URL, path or title.
+func (m *ExternalManager) ReadPage(ctx context.Context, serviceName, pageRef, ref string, includeSubsections bool) (*PageContent, error) {
+	index, err := m.ListPages(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	info, ok := findPage(index, pageRef)
+	if !ok {
+		return nil, fmt.Errorf("page not found: %s in %s", pageRef, serviceName)
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.pagesDir(serviceName), info.File))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page %s: %w", info.ID, err)
+	}
+	content := stripFrontMatter(string(data))
+
+	page := &PageContent{Service: serviceName, Page: info, Content: content}
+	if ref == "" {
+		return page, nil
+	}
+
+	section, ok := docs.ExtractSection(content, ref, includeSubsections)
+	if !ok {
+		return nil, fmt.Errorf("section not found: %s in %s", ref, info.ID)
+	}
+	page.Section = section
+	page.Content = section.Content
+	return page, nil
+}
+
+// findPage resolves a page reference against the index of a service
+func findPage(index []PageInfo, ref string) (PageInfo, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return PageInfo{}, false
+	}
+	id := strings.TrimSuffix(ref, ".md")
+
+	// IDs win over URLs, URLs over case-insensitive titles
+	for _, page := range index {
+		if page.ID == id {
+			return page, true
+		}
+	}
+	for _, page := range index {
+		if sameURL(page.URL, ref) {
+			return page, true
+		}
+	}
+	for _, page := range index {
+		if strings.EqualFold(page.Title, ref) {
+			return page, true
+		}
+	}
+
+	return PageInfo{}, false
+}
+
+// sameURL reports whether ref is pageURL, or its path, ignoring a trailing slash
+func sameURL(pageURL, ref string) bool {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return false
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return false
+	}
+	if r.Host != "" && r.Host != u.Host {
+		return false
+	}
+	return strings.TrimSuffix(u.Path, "/") == strings.TrimSuffix(r.Path, "/") && r.Path != ""
+}
+
+// pagesDir returns the directory holding the crawled pages of a service
+func (m *ExternalManager) pagesDir(serviceName string) string {
+	return filepath.Join(m.cachePath, serviceName)
+}
+
+// savePages replaces the cached pages of a service with one Markdown file per
+// page, their title and URL in front matter, and an index of them
+func (m *ExternalManager) savePages(serviceName string, pages []Page) error {
+	if m.cachePath == "" {
+		return fmt.Errorf("cache path not configured")
+	}
+
+	dir := m.pagesDir(serviceName)
+	tmpDir := dir + ".tmp"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return err
+	}
+
+	index := make([]PageInfo, 0, len(pages))
+	used := make(map[string]bool)
+	for _, page := range pages {
+		id := pageSlug(page.URL)
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", pageSlug(page.URL), n)
+		}
+		used[id] = true
+
+		title := page.Title
+		if title == "" {
+			title = id
+		}
+
+		info := PageInfo{
+			ID:    id,
+			Title: title,
+			URL:   page.URL,
+			File:  id + ".md",
+			Size:  len(page.Content),
+		}
+		for _, sec := range docs.ParseSections(page.Content) {
+			if sec.Level > 0 {
+				info.Sections = append(info.Sections, PageSection{Title: sec.Title, Level: sec.Level, Anchor: sec.Anchor})
+			}
+		}
+		index = append(index, info)
+
+		content := fmt.Sprintf("---\ntitle: %s\nurl: %s\n---\n\n%s\n", title, page.URL, page.Content)
+		if err := os.WriteFile(filepath.Join(tmpDir, info.File), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, indexFile), data, 0644); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpDir, dir); err != nil {
+		return err
+	}
+
+	// Drop the single-file cache written by earlier versions
+	_ = os.Remove(filepath.Join(m.cachePath, fmt.Sprintf("%s_docs.txt", serviceName)))
+	return nil
+}
+
+// loadIndex reads the index of a service's cached pages
+func (m *ExternalManager) loadIndex(serviceName string) ([]PageInfo, error) {
+	if m.cachePath == "" {
+		return nil, fmt.Errorf("cache path not configured")
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.pagesDir(serviceName), indexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s documentation is not cached", serviceName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page index: %w", err)
+	}
+
+	var index []PageInfo
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid page index: %w", err)
+	}
+	return index, nil
+}
+
+// cachedPages reads every cached page of a service in crawl order. Caches
+// written by earlier versions hold a single file, returned as one page.
+func (m *ExternalManager) cachedPages(serviceName string) ([]cachedPage, error) {
+	index, err := m.loadIndex(serviceName)
+	if err != nil {
+		data, legacyErr := os.ReadFile(filepath.Join(m.cachePath, fmt.Sprintf("%s_docs.txt", serviceName)))
+		if legacyErr != nil {
+			return nil, err
+		}
+		config, _ := m.Service(serviceName)
+		return []cachedPage{{PageInfo: PageInfo{Title: config.Name, URL: config.URL}, Content: string(data)}}, nil
+	}
+
+	pages := make([]cachedPage, 0, len(index))
+	for _, info := range index {
+		data, err := os.ReadFile(filepath.Join(m.pagesDir(serviceName), info.File))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %s: %w", info.ID, err)
+		}
+		pages = append(pages, cachedPage{PageInfo: info, Content: stripFrontMatter(string(data))})
+	}
+	return pages, nil
+}
+
+// stripFrontMatter returns the Markdown content of a cached page file
+func stripFrontMatter(data string) string {
+	rest, ok := strings.CutPrefix(data, "---\n")
+	if !ok {
+		return strings.TrimSpace(data)
+	}
+	if _, body, found := strings.Cut(rest, "\n---\n"); found {
+		return strings.TrimSpace(body)
+	}
+	return strings.TrimSpace(data)
+}
+
+// pageSlug turns the path of a page URL into a file name
+func pageSlug(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "page"
+	}
+
+	var slug strings.Builder
+	for _, r := range strings.ToLower(strings.Trim(u.Path, "/")) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			slug.WriteRune(r)
+		default:
+			if s := slug.String(); s != "" && !strings.HasSuffix(s, "-") {
+				slug.WriteRune('-')
+			}
+		}
+	}
+
+	name := strings.Trim(slug.String(), "-")
+	if name == "" {
+		return "index"
+	}
+	if len(name) > 80 {
+		name = strings.TrimRight(name[:80], "-")
+	}
+	return name
+}
@@ -0,0 +1,181 @@
+package external
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// serviceIDPattern keeps service IDs usable as cache directory names
+var serviceIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ServiceConfig describes an external Laravel service and where its documentation lives
+type ServiceConfig struct {
+	ID          string          `json:"id" yaml:"id"`
+	Name        string          `json:"name" yaml:"name"`
+	URL         string          `json:"url" yaml:"url"` // documentation root the crawl starts at
+	Website     string          `json:"website,omitempty" yaml:"website,omitempty"`
+	Type        string          `json:"type,omitempty" yaml:"type,omitempty"`
+	Summary     string          `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string          `json:"description,omitempty" yaml:"description,omitempty"`
+	Features    []string        `json:"features,omitempty" yaml:"features,omitempty"`
+	Crawl       *CrawlOverrides `json:"crawl,omitempty" yaml:"crawl,omitempty"`
+}
+
+// CrawlOverrides replace the default crawl rules for one service. Unset fields
+// keep the default.
+type CrawlOverrides struct {
+	MaxDepth *int     `json:"max_depth,omitempty" yaml:"max_depth,omitempty"`
+	MaxPages *int     `json:"max_pages,omitempty" yaml:"max_pages,omitempty"`
+	Delay    string   `json:"delay,omitempty" yaml:"delay,omitempty"`     // duration such as "2s"
+	Include  []string `json:"include,omitempty" yaml:"include,omitempty"` // path prefixes such as "/docs/guides"
+	Exclude  []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
+
+// apply returns the rules with the overrides applied
+func (o *CrawlOverrides) apply(rules CrawlRules) CrawlRules {
+	if o == nil {
+		return rules
+	}
+	if o.MaxDepth != nil {
+		rules.MaxDepth = *o.MaxDepth
+	}
+	if o.MaxPages != nil {
+		rules.MaxPages = *o.MaxPages
+	}
+	if delay, err := time.ParseDuration(o.Delay); err == nil {
+		rules.Delay = delay
+	}
+	if len(o.Include) > 0 {
+		rules.Include = o.Include
+	}
+	if len(o.Exclude) > 0 {
+		rules.Exclude = o.Exclude
+	}
+	return rules
+}
+
+// DefaultServices are the services known without a services config
+var DefaultServices = []ServiceConfig{
+	{
+		ID:          "forge",
+		Name:        "Laravel Forge",
+		URL:         "https://forge.laravel.com/docs",
+		Website:     "https://forge.laravel.com",
+		Type:        "Server Management & Deployment Platform",
+		Summary:     "Automated server management and deployment platform for Laravel applications.",
+		Description: "Laravel Forge is a server management and deployment platform that makes it easy to deploy and manage your Laravel applications on any cloud provider.",
+		Features:    []string{"Automated server provisioning", "Zero-downtime deployments", "SSL certificate management", "Database management", "Queue worker monitoring", "Scheduled job management"},
+	},
+	{
+		ID:          "vapor",
+		Name:        "Laravel Vapor",
+		URL:         "https://docs.vapor.build",
+		Website:     "https://vapor.laravel.com",
+		Type:        "Serverless Deployment Platform",
+		Summary:     "Serverless deployment platform for Laravel on AWS Lambda.",
+		Description: "Laravel Vapor is an auto-scaling, serverless deployment platform for Laravel, powered by AWS Lambda.",
+		Features:    []string{"Serverless infrastructure", "Auto-scaling capabilities", "Global CDN integration", "Database management", "Cache and queue services", "Environment management"},
+	},
+	{
+		ID:          "envoyer",
+		Name:        "Laravel Envoyer",
+		URL:         "https://envoyer.io/docs",
+		Website:     "https://envoyer.io",
+		Type:        "Zero-Downtime Deployment",
+		Summary:     "Zero-downtime deployment platform for PHP applications.",
+		Description: "Envoyer is a zero-downtime deployment platform for PHP applications, including Laravel.",
+		Features:    []string{"Zero-downtime deployments", "Health checks", "Deployment hooks", "Team collaboration", "Deployment monitoring", "Rollback capabilities"},
+	},
+	{
+		ID:          "nova",
+		Name:        "Laravel Nova",
+		URL:         "https://nova.laravel.com/docs",
+		Website:     "https://nova.laravel.com",
+		Type:        "Administration Panel",
+		Summary:     "Administration panel for Laravel applications.",
+		Description: "Laravel Nova is a beautifully-designed administration panel for Laravel applications.",
+		Features:    []string{"Resource management", "Custom metrics and cards", "Action execution", "Advanced filters", "Relationship management", "Authorization"},
+	},
+}
+
+// registryFile is the layout of a services config
+type registryFile struct {
+	Services []ServiceConfig `json:"services" yaml:"services"`
+}
+
+// LoadServices reads the services config at path, as YAML when the file ends in
+// .yaml or .yml and as JSON otherwise
+func LoadServices(path string) ([]ServiceConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read services config: %w", err)
+	}
+
+	var file registryFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse services config: %w", err)
+	}
+
+	if err := validateServices(file.Services); err != nil {
+		return nil, fmt.Errorf("invalid services config: %w", err)
+	}
+	return file.Services, nil
+}
+
+// validateServices checks the services of a config and fills in optional fields
+func validateServices(services []ServiceConfig) error {
+	if len(services) == 0 {
+		return fmt.Errorf("no services defined")
+	}
+
+	seen := make(map[string]bool)
+	for i := range services {
+		service := &services[i]
+		if !serviceIDPattern.MatchString(service.ID) {
+			return fmt.Errorf("service %d: id %q must be lowercase letters, digits, '-' or '_'", i+1, service.ID)
+		}
+		if seen[service.ID] {
+			return fmt.Errorf("service %s is defined twice", service.ID)
+		}
+		seen[service.ID] = true
+
+		u, err := url.Parse(service.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("service %s: url must be an absolute http(s) URL", service.ID)
+		}
+		if service.Crawl != nil && service.Crawl.Delay != "" {
+			if _, err := time.ParseDuration(service.Crawl.Delay); err != nil {
+				return fmt.Errorf("service %s: invalid crawl delay: %w", service.ID, err)
+			}
+		}
+		if service.Crawl != nil {
+			for _, prefix := range append(service.Crawl.Include, service.Crawl.Exclude...) {
+				if !strings.HasPrefix(prefix, "/") {
+					return fmt.Errorf("service %s: crawl path prefix %q must start with '/'", service.ID, prefix)
+				}
+			}
+		}
+
+		if service.Name == "" {
+			service.Name = service.ID
+		}
+		if service.Website == "" {
+			service.Website = (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()
+		}
+	}
+	return nil
+}
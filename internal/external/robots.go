@@ -0,0 +1,170 @@
+package external
+
+import (
+	"bufio"
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// robotsRules are the robots.txt rules that apply to the scraper on one host
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+	sitemaps   []string
+}
+
+// robotsRule allows or disallows the paths its pattern matches
+type robotsRule struct {
+	allow   bool
+	length  int // pattern length; the longest matching pattern wins
+	pattern *regexp.Regexp
+}
+
+// parseRobots reads the group of a robots.txt file that applies to agent,
+// falling back to the "*" group
+func parseRobots(body, agent string) *robotsRules {
+	type group struct {
+		agents     []string
+		rules      []robotsRule
+		crawlDelay time.Duration
+	}
+
+	robots := &robotsRules{}
+	var groups []*group
+	var current *group
+	inAgents := false
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share one group
+			if !inAgents {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			inAgents = true
+			continue
+		case "sitemap":
+			robots.sitemaps = append(robots.sitemaps, value)
+		case "allow", "disallow":
+			if current != nil && value != "" {
+				current.rules = append(current.rules, robotsRule{
+					allow:   key == "allow",
+					length:  len(value),
+					pattern: robotsPattern(value),
+				})
+			}
+		case "crawl-delay":
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && current != nil {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+		inAgents = false
+	}
+
+	token := strings.ToLower(strings.SplitN(agent, "/", 2)[0])
+	var fallback *group
+	for _, g := range groups {
+		for _, a := range g.agents {
+			if a == "*" && fallback == nil {
+				fallback = g
+			} else if a != "*" && strings.Contains(token, a) {
+				robots.rules, robots.crawlDelay = g.rules, g.crawlDelay
+				return robots
+			}
+		}
+	}
+	if fallback != nil {
+		robots.rules, robots.crawlDelay = fallback.rules, fallback.crawlDelay
+	}
+	return robots
+}
+
+// robotsPattern compiles a robots.txt path pattern, where "*" matches any
+// characters and a trailing "$" anchors the end of the path
+func robotsPattern(value string) *regexp.Regexp {
+	anchored := strings.HasSuffix(value, "$")
+	value = strings.TrimSuffix(value, "$")
+
+	parts := strings.Split(value, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allowed reports whether a path, including its query, may be crawled
+func (r *robotsRules) allowed(path string) bool {
+	if r == nil {
+		return true
+	}
+
+	allow, best := true, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		// Allow wins a tie between equally specific rules
+		if rule.length > best || rule.length == best && rule.allow {
+			allow, best = rule.allow, rule.length
+		}
+	}
+	return allow
+}
+
+// hostLimiter spaces out requests to the same host
+type hostLimiter struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// newHostLimiter creates a limiter with no requests made yet
+func newHostLimiter() *hostLimiter {
+	return &hostLimiter{next: make(map[string]time.Time)}
+}
+
+// wait blocks until a request to host may be sent, then reserves the next
+// slot delay later
+func (l *hostLimiter) wait(ctx context.Context, host string, delay time.Duration) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(delay)
+	l.mu.Unlock()
+
+	wait := time.Until(at)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
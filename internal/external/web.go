@@ -1,6 +1,7 @@
 package external
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,9 +15,13 @@ const (
 	requestTimeout = 15 * time.Second
 )
 
+// userAgent identifies the scraper to web servers and robots.txt rules
+const userAgent = "Laravel-MCP-Companion/1.0"
+
 // WebScraper handles fetching external web resources
 type WebScraper struct {
 	httpClient *http.Client
+	limiter    *hostLimiter
 }
 
 // NewWebScraper creates a new web scraper
@@ -31,108 +36,90 @@ func NewWebScraper() *WebScraper {
 				return nil
 			},
 		},
+		limiter: newHostLimiter(),
 	}
 }
 
+// fetchedPage is a response body together with the URL that served it
+type fetchedPage struct {
+	URL         *url.URL
+	ContentType string
+	Body        string
+}
+
+// isHTML reports whether the page is an HTML document
+func (p *fetchedPage) isHTML() bool {
+	return strings.Contains(p.ContentType, "text/html")
+}
+
 // FetchResource fetches content from a URL
-func (w *WebScraper) FetchResource(urlStr string) (string, error) {
+func (w *WebScraper) FetchResource(ctx context.Context, urlStr string) (string, error) {
+	page, err := w.fetch(ctx, urlStr, 0)
+	if err != nil {
+		return "", err
+	}
+
+	// Convert HTML pages to Markdown so searches match the text, not the markup
+	if page.isHTML() {
+		return htmlToMarkdown(page.Body, page.URL), nil
+	}
+	return page.Body, nil
+}
+
+// fetch downloads a URL, waiting at least delay since the previous request to its host
+func (w *WebScraper) fetch(ctx context.Context, urlStr string, delay time.Duration) (*fetchedPage, error) {
 	// Validate URL
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
-		return "", fmt.Errorf("invalid URL: %w", err)
+		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Only allow HTTP/HTTPS
 	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		return "", fmt.Errorf("only HTTP/HTTPS URLs are supported")
+		return nil, fmt.Errorf("only HTTP/HTTPS URLs are supported")
+	}
+
+	if err := w.limiter.wait(ctx, parsedURL.Host, delay); err != nil {
+		return nil, err
 	}
 
 	// Perform request
-	req, err := http.NewRequest("GET", urlStr, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", "Laravel-MCP-Companion/1.0")
+	req.Header.Set("User-Agent", userAgent)
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 
 	resp, err := w.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	// Limit content size
 	limitedReader := io.LimitReader(resp.Body, maxContentSize)
 	content, err := io.ReadAll(limitedReader)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Check if we hit the size limit
 	if len(content) >= maxContentSize {
-		return "", fmt.Errorf("content too large (max: %d bytes)", maxContentSize)
-	}
-
-	// Convert to string and basic cleanup
-	contentStr := string(content)
-	contentType := resp.Header.Get("Content-Type")
-
-	// If HTML, try to extract main content
-	if strings.Contains(contentType, "text/html") {
-		contentStr = w.extractMainContent(contentStr)
+		return nil, fmt.Errorf("content too large (max: %d bytes)", maxContentSize)
 	}
 
-	return contentStr, nil
-}
-
-// extractMainContent attempts to extract main content from HTML
-func (w *WebScraper) extractMainContent(html string) string {
-	// Simple heuristic: remove script and style tags
-	result := html
-
-	// Remove script tags
-	result = removeTagsWithContent(result, "<script", "</script>")
-
-	// Remove style tags
-	result = removeTagsWithContent(result, "<style", "</style>")
-
-	// Remove excessive whitespace
-	lines := strings.Split(result, "\n")
-	var cleanLines []string
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed != "" {
-			cleanLines = append(cleanLines, trimmed)
-		}
-	}
-
-	return strings.Join(cleanLines, "\n")
-}
-
-// removeTagsWithContent removes HTML tags and their content
-func removeTagsWithContent(text, startTag, endTag string) string {
-	result := text
-	for {
-		startIdx := strings.Index(result, startTag)
-		if startIdx == -1 {
-			break
-		}
-
-		endIdx := strings.Index(result[startIdx:], endTag)
-		if endIdx == -1 {
-			break
-		}
-
-		// Remove from start tag to end of end tag
-		result = result[:startIdx] + result[startIdx+endIdx+len(endTag):]
-	}
-	return result
+	return &fetchedPage{
+		URL:         resp.Request.URL,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(content),
+	}, nil
 }
 
 // FormatResource formats fetched content for display
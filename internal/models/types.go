@@ -1,6 +1,12 @@
 package models
 
-import "time"
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // DocMetadata represents metadata about documentation
 type DocMetadata struct {
@@ -8,14 +14,47 @@ type DocMetadata struct {
 	CommitSHA string    `json:"commit_sha"`
 	SyncTime  time.Time `json:"sync_time"`
 	FileCount int       `json:"file_count"`
+
+	// Files maps each documentation file to its git blob SHA at CommitSHA
+	Files map[string]string `json:"files,omitempty"`
 }
 
-// SupportedVersions list of Laravel versions
+// SupportedVersions list of Laravel versions known at build time. Newer versions
+// are discovered from the docs source and from directories on disk.
 var SupportedVersions = []string{
 	"12.x", "11.x", "10.x", "9.x",
 	"8.x", "7.x", "6.x",
 }
 
+// SwapBackupPrefix is prepended to a version name for the live copy of its docs
+// that an update moves aside while the new copy is renamed into place
+const SwapBackupPrefix = ".previous-"
+
+// versionName matches documentation version names such as 12.x
+var versionName = regexp.MustCompile(`^\d+\.x$`)
+
+// IsVersionName reports whether name looks like a documentation version such as 12.x
+func IsVersionName(name string) bool {
+	return versionName.MatchString(name)
+}
+
+// SortVersions orders version names newest first
+func SortVersions(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return VersionMajor(versions[i]) > VersionMajor(versions[j])
+	})
+}
+
+// VersionMajor returns the major number of a version such as 12.x, or -1
+func VersionMajor(version string) int {
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
 // DefaultVersion is the latest stable version
 const DefaultVersion = "12.x"
 
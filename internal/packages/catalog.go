@@ -1,6 +1,7 @@
 package packages
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -63,11 +64,14 @@ func (c *Catalog) GetCategory(categoryName string) (*models.PackageCategory, err
 }
 
 // Search finds packages matching a query
-func (c *Catalog) Search(query string, filters map[string]interface{}) []models.Package {
+func (c *Catalog) Search(ctx context.Context, query string, filters map[string]interface{}) ([]models.Package, error) {
 	query = strings.ToLower(query)
 	var results []models.Package
 
 	for _, category := range c.data.Categories {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		for _, pkg := range category.Packages {
 			if c.matchesSearch(pkg, query, filters) {
 				results = append(results, pkg)
@@ -80,7 +84,7 @@ func (c *Catalog) Search(query string, filters map[string]interface{}) []models.
 		return results[i].PopularityScore > results[j].PopularityScore
 	})
 
-	return results
+	return results, nil
 }
 
 // matchesSearch checks if package matches search criteria
@@ -142,7 +146,7 @@ func (c *Catalog) matchesSearch(pkg models.Package, query string, filters map[st
 }
 
 // Recommend returns package recommendations based on use case
-func (c *Catalog) Recommend(useCase string, limit int) []models.Package {
+func (c *Catalog) Recommend(ctx context.Context, useCase string, limit int) ([]models.Package, error) {
 	useCase = strings.ToLower(useCase)
 	var scored []struct {
 		pkg   models.Package
@@ -150,6 +154,9 @@ func (c *Catalog) Recommend(useCase string, limit int) []models.Package {
 	}
 
 	for _, category := range c.data.Categories {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		for _, pkg := range category.Packages {
 			score := c.calculateRelevanceScore(pkg, useCase)
 			if score > 0 {
@@ -178,7 +185,7 @@ func (c *Catalog) Recommend(useCase string, limit int) []models.Package {
 		results = append(results, s.pkg)
 	}
 
-	return results
+	return results, nil
 }
 
 // calculateRelevanceScore scores a package's relevance to a use case
@@ -214,10 +221,13 @@ func (c *Catalog) calculateRelevanceScore(pkg models.Package, useCase string) in
 }
 
 // GetPackage returns details for a specific package
-func (c *Catalog) GetPackage(composerName string) (*models.Package, error) {
+func (c *Catalog) GetPackage(ctx context.Context, composerName string) (*models.Package, error) {
 	composerName = strings.ToLower(composerName)
 
 	for _, category := range c.data.Categories {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		for _, pkg := range category.Packages {
 			if strings.ToLower(pkg.ComposerName) == composerName {
 				return &pkg, nil
@@ -229,9 +239,9 @@ func (c *Catalog) GetPackage(composerName string) (*models.Package, error) {
 }
 
 // GetFeatures returns common implementation features for a package
-func (c *Catalog) GetFeatures(packageName string) []string {
+func (c *Catalog) GetFeatures(ctx context.Context, packageName string) []string {
 	// Get package
-	pkg, err := c.GetPackage(packageName)
+	pkg, err := c.GetPackage(ctx, packageName)
 	if err != nil {
 		return []string{}
 	}
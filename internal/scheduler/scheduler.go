@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	mcperrors "github.com/izzamoe/laravel-mcp-companion-go/internal/errors"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/external"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/logging"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/updater"
+)
+
+// jitterFraction is how far a wait may randomly deviate from the interval, so
+// many servers started together do not hit GitHub at the same moment
+const jitterFraction = 0.1
+
+// Scheduler periodically refreshes downloaded documentation and expired external caches
+type Scheduler struct {
+	updater       *updater.GitHubUpdater
+	external      *external.ExternalManager
+	interval      time.Duration
+	onDocsChanged func(version string)
+}
+
+// New creates a scheduler that refreshes content every interval
+func New(upd *updater.GitHubUpdater, externalManager *external.ExternalManager, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		updater:  upd,
+		external: externalManager,
+		interval: interval,
+	}
+}
+
+// OnDocsChanged sets a callback run after a refresh changed a version's files,
+// typically to rebuild its search index
+func (s *Scheduler) OnDocsChanged(fn func(version string)) {
+	s.onDocsChanged = fn
+}
+
+// Run refreshes content every interval, with jitter, until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	logging.Info("Scheduled refresh every %s", s.interval)
+
+	for {
+		timer := time.NewTimer(jittered(s.interval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.RefreshOnce(ctx)
+	}
+}
+
+// RefreshOnce checks every downloaded version against its branch head and
+// re-fetches expired external service caches
+func (s *Scheduler) RefreshOnce(ctx context.Context) {
+	start := time.Now()
+	s.refreshDocs(ctx)
+	s.refreshExternal(ctx)
+	logging.Info("Scheduled refresh finished in %s", time.Since(start).Round(time.Millisecond))
+}
+
+// refreshDocs syncs every downloaded version
+func (s *Scheduler) refreshDocs(ctx context.Context) {
+	versions := s.updater.LocalVersions()
+	logging.Info("Scheduled refresh: checking %d documentation versions", len(versions))
+
+	for _, version := range versions {
+		if ctx.Err() != nil {
+			return
+		}
+
+		report, err := s.updater.SyncDocs(ctx, version, false, nil)
+		if err != nil {
+			logging.Warn("Scheduled refresh of %s failed: %v", version, err)
+
+			// The remaining versions would hit the same rate limit
+			var mcpErr *mcperrors.MCPError
+			if errors.As(err, &mcpErr) && mcpErr.Code == mcperrors.ErrGitHubAPI {
+				return
+			}
+			continue
+		}
+
+		if report.UpToDate || !report.Changed() {
+			logging.Debug("Scheduled refresh: %s is up to date", version)
+			continue
+		}
+
+		logging.Info("Scheduled refresh: %s updated (%d added, %d modified, %d removed)",
+			version, len(report.Added), len(report.Modified), len(report.Removed))
+		if s.onDocsChanged != nil {
+			s.onDocsChanged(version)
+		}
+	}
+}
+
+// refreshExternal re-fetches external services whose cache has expired
+func (s *Scheduler) refreshExternal(ctx context.Context) {
+	if s.external == nil || ctx.Err() != nil {
+		return
+	}
+
+	stale := s.external.StaleServices()
+	if len(stale) == 0 {
+		return
+	}
+
+	logging.Info("Scheduled refresh: updating %d expired external services", len(stale))
+	if _, err := s.external.UpdateServices(ctx, stale, false, nil); err != nil {
+		logging.Warn("Scheduled refresh of external services failed: %v", err)
+	}
+}
+
+// jittered returns d randomly adjusted by up to jitterFraction in either direction
+func jittered(d time.Duration) time.Duration {
+	spread := float64(d) * jitterFraction
+	return d + time.Duration((rand.Float64()*2-1)*spread)
+}
@@ -0,0 +1,26 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+// jittered is unexported, so it is tested here; refreshes are tested through
+// the public API in tests/scheduler_test.go
+func TestJittered(t *testing.T) {
+	interval := time.Hour
+	low := interval - time.Duration(float64(interval)*jitterFraction)
+	high := interval + time.Duration(float64(interval)*jitterFraction)
+
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 1000; i++ {
+		d := jittered(interval)
+		if d < low || d > high {
+			t.Fatalf("Expected a wait within [%s, %s], got %s", low, high, d)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 2 {
+		t.Error("Expected the waits to vary")
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/logging"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// BootstrapDocs downloads a version in the background, for a first run with an
+// empty docs path. Tools asking for the version report sync progress meanwhile.
+func (s *Server) BootstrapDocs(ctx context.Context, version string) {
+	s.bootstrapMu.Lock()
+	s.bootstrapping = version
+	s.bootstrapMu.Unlock()
+
+	go func() {
+		defer func() {
+			s.bootstrapMu.Lock()
+			s.bootstrapping = ""
+			s.bootstrapMu.Unlock()
+		}()
+
+		logging.Info("Downloading Laravel %s documentation for first use...", version)
+		report, err := s.updater.SyncDocs(ctx, version, false, nil)
+		if err != nil {
+			logging.Warn("Failed to download Laravel %s documentation: %v (run update_laravel_docs to retry)", version, err)
+			return
+		}
+
+		if err := s.docManager.RefreshIndex(ctx, version); err != nil {
+			logging.Warn("Failed to build search index for %s: %v", version, err)
+		}
+		s.RefreshDocResources(ctx, version)
+		logging.Info("Downloaded Laravel %s documentation (%d files)", version, len(report.Added)+len(report.Modified)+report.Unchanged)
+	}()
+}
+
+// syncingResult returns a status result while versions that are not on disk yet
+// are being downloaded, or nil when the tool can run normally. An empty version
+// targets no version in particular, like an all-versions search, and never blocks.
+func (s *Server) syncingResult(versions ...string) *mcp.CallToolResult {
+	if s.updater == nil {
+		return nil
+	}
+
+	s.bootstrapMu.Lock()
+	bootstrapping := s.bootstrapping
+	s.bootstrapMu.Unlock()
+
+	for _, version := range versions {
+		if version == "" || s.docManager.HasVersion(version) {
+			continue
+		}
+
+		done, total, syncing := s.updater.SyncProgress(version)
+		if !syncing && version != bootstrapping {
+			continue
+		}
+
+		percent := 0
+		if total > 0 {
+			percent = done * 100 / total
+		}
+		status := fmt.Sprintf("Laravel %s documentation is syncing, %d%% complete", version, percent)
+		if total > 0 {
+			status += fmt.Sprintf(" (%d of %d files)", done, total)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: status + ". Try again in a moment."}},
+		}
+	}
+
+	return nil
+}
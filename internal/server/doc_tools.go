@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/docs"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-// Tool input/output types for all 6 documentation tools
+// Tool input/output types for all documentation tools
 type ListDocsInput struct {
 	Version string `json:"version,omitempty" jsonschema:"Specific Laravel version to list (e.g. '12.x'). If not provided lists all versions"`
 }
@@ -31,6 +32,13 @@ type SearchWithContextInput struct {
 	IncludeExternal *bool  `json:"include_external,omitempty" jsonschema:"Include external services"`
 }
 
+type ReadSectionInput struct {
+	Filename           string `json:"filename" jsonschema:"required,Name of the file (e.g. 'queries.md')"`
+	Section            string `json:"section" jsonschema:"required,Heading text slug or anchor of the section (e.g. 'Basic Routing' or 'basic-routing')"`
+	Version            string `json:"version,omitempty" jsonschema:"Laravel version (e.g. '12.x'). Defaults to latest"`
+	IncludeSubsections *bool  `json:"include_subsections,omitempty" jsonschema:"Include nested subsections below the heading (default: false)"`
+}
+
 type GetStructureInput struct {
 	Filename string `json:"filename" jsonschema:"required,Documentation file name"`
 	Version  string `json:"version,omitempty" jsonschema:"Laravel version"`
@@ -41,27 +49,50 @@ type BrowseCategoryInput struct {
 	Version  string `json:"version,omitempty" jsonschema:"Laravel version"`
 }
 
-// Empty output types - we return text content
-type EmptyOutput struct{}
+type DiffDocsInput struct {
+	Filename    string `json:"filename" jsonschema:"required,Documentation file name (e.g. 'queues.md')"`
+	FromVersion string `json:"from_version" jsonschema:"required,Version to compare from (e.g. '10.x')"`
+	ToVersion   string `json:"to_version" jsonschema:"required,Version to compare to (e.g. '12.x')"`
+}
 
-// RegisterDocTools registers all 6 documentation-related MCP tools
+type UpgradeGuideInput struct {
+	FromVersion string `json:"from_version" jsonschema:"required,Version the application runs today (e.g. '10.x')"`
+	ToVersion   string `json:"to_version" jsonschema:"required,Version to upgrade to (e.g. '12.x')"`
+	MinImpact   string `json:"min_impact,omitempty" jsonschema:"Only include changes at or above this likelihood of impact: high medium low 'very low' or optional"`
+	Component   string `json:"component,omitempty" jsonschema:"Only include changes affecting this component (e.g. 'queues' 'eloquent' 'authentication')"`
+	Details     *bool  `json:"details,omitempty" jsonschema:"Include the full text of each change (default: true)"`
+}
+
+const (
+	// maxSearchResults limits the structured results of a single-version search
+	maxSearchResults = 25
+
+	// maxSearchResultsPerVersion limits the structured results per version of an all-versions search
+	maxSearchResultsPerVersion = 10
+)
+
+// RegisterDocTools registers all documentation-related MCP tools
 func (s *Server) RegisterDocTools() error {
 	// Tool 1: list_laravel_docs
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "list_laravel_docs",
 		Description: "List all available Laravel documentation files across versions. Essential for discovering what documentation exists before diving into specific topics.\n\nWhen to use:\n- Initial exploration of Laravel documentation\n- Finding available documentation files\n- Checking which versions have specific documentation\n- Getting an overview of documentation coverage",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input ListDocsInput) (*mcp.CallToolResult, EmptyOutput, error) {
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ListDocsInput) (*mcp.CallToolResult, DocsListOutput, error) {
 		version := input.Version
 		if version == "" {
-			version = "12.x"
+			version = s.docManager.DefaultVersion()
+		}
+
+		if result := s.syncingResult(version); result != nil {
+			return result, DocsListOutput{}, nil
 		}
 
-		files, err := s.docManager.ListDocs(version)
+		files, err := s.docManager.ListDocs(ctx, version)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list docs: %v", err)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, DocsListOutput{}, nil
 		}
 
 		var result strings.Builder
@@ -73,73 +104,79 @@ func (s *Server) RegisterDocTools() error {
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result.String()}},
-		}, EmptyOutput{}, nil
+		}, DocsListOutput{Version: version, Files: files}, nil
 	})
 
 	// Tool 2: read_laravel_doc_content
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "read_laravel_doc_content",
 		Description: "Reads the complete content of a specific Laravel documentation file. This is the primary tool for accessing actual documentation content.\n\nWhen to use:\n- Reading full documentation for a feature\n- Getting complete implementation details\n- Accessing code examples from docs\n- Understanding concepts in depth",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input ReadDocInput) (*mcp.CallToolResult, EmptyOutput, error) {
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ReadDocInput) (*mcp.CallToolResult, DocContentOutput, error) {
 		if input.Filename == "" {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: "filename is required"}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, DocContentOutput{}, nil
 		}
 
-		content, err := s.docManager.ReadDoc(input.Version, input.Filename)
+		version := input.Version
+		if version == "" {
+			version = s.docManager.DefaultVersion()
+		}
+
+		if result := s.syncingResult(version); result != nil {
+			return result, DocContentOutput{}, nil
+		}
+
+		content, err := s.docManager.ReadDoc(ctx, version, input.Filename)
 		if err != nil {
 			// Check if it's a "document not found" error and we have an updater
 			if strings.Contains(err.Error(), "document not found") && s.updater != nil {
 				// Try to download the file from GitHub
-				version := input.Version
-				if version == "" {
-					version = "12.x"
-				}
 
-				downloadedContent, downloadErr := s.updater.DownloadSingleFile(version, input.Filename)
+				downloadedContent, downloadErr := s.updater.DownloadSingleFile(ctx, version, input.Filename)
 				if downloadErr != nil {
 					return &mcp.CallToolResult{
 						Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to read doc: %v. Also failed to download from GitHub: %v", err, downloadErr)}},
 						IsError: true,
-					}, EmptyOutput{}, nil
+					}, DocContentOutput{}, nil
 				}
 
-				// Clear cache to ensure fresh read
+				// Clear cache and rebuild the index to ensure fresh reads and searches
 				s.docManager.ClearCache()
+				_ = s.docManager.RefreshIndex(ctx, version)
 
 				// Now try to read again
-				content, err = s.docManager.ReadDoc(input.Version, input.Filename)
+				content, err = s.docManager.ReadDoc(ctx, version, input.Filename)
 				if err != nil {
 					// If still error, return the downloaded content directly
 					return &mcp.CallToolResult{
 						Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Downloaded from GitHub but failed to read locally: %v\n\nContent:\n%s", err, downloadedContent)}},
-					}, EmptyOutput{}, nil
+					}, DocContentOutput{Version: version, Filename: input.Filename, Content: downloadedContent}, nil
 				}
 			} else {
 				return &mcp.CallToolResult{
 					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to read doc: %v", err)}},
 					IsError: true,
-				}, EmptyOutput{}, nil
+				}, DocContentOutput{}, nil
 			}
 		}
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: content}},
-		}, EmptyOutput{}, nil
+		}, DocContentOutput{Version: version, Filename: input.Filename, Content: content}, nil
 	})
 
 	// Tool 3: search_laravel_docs
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "search_laravel_docs",
-		Description: "Searches for specific terms across all Laravel documentation files. Returns file names and match counts.\n\nWhen to use:\n- Finding which files contain specific topics\n- Getting quick overview of where a concept is mentioned\n- Discovering related documentation\n- Checking documentation coverage for a feature",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input SearchDocsInput) (*mcp.CallToolResult, EmptyOutput, error) {
+		Description: "Searches for specific terms across all Laravel documentation files. Returns files ranked by relevance with the best matching sections as 'file#anchor' references and their scores.\n\nWhen to use:\n- Finding which files contain specific topics\n- Getting quick overview of where a concept is mentioned\n- Discovering related documentation\n- Checking documentation coverage for a feature",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input SearchDocsInput) (*mcp.CallToolResult, SearchOutput, error) {
 		if input.Query == "" {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: "query is required"}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, SearchOutput{}, nil
 		}
 
 		version := input.Version
@@ -148,16 +185,20 @@ func (s *Server) RegisterDocTools() error {
 			includeExternal = *input.IncludeExternal
 		}
 
-		results, err := s.docManager.SearchDocs(input.Query, version)
+		if result := s.syncingResult(version); result != nil {
+			return result, SearchOutput{}, nil
+		}
+
+		results, output, err := s.search(ctx, input.Query, version)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Search failed: %v", err)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, SearchOutput{}, nil
 		}
 
 		if includeExternal && s.externalManager != nil {
-			externalResults, err := s.externalManager.SearchServices(input.Query, nil)
+			externalResults, err := s.externalManager.SearchServices(ctx, input.Query, nil)
 			if err == nil && externalResults != "" {
 				results += "\n\n---\n\n" + externalResults
 			}
@@ -165,41 +206,52 @@ func (s *Server) RegisterDocTools() error {
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: results}},
-		}, EmptyOutput{}, nil
+		}, output, nil
 	})
 
 	// Tool 4: search_laravel_docs_with_context
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "search_laravel_docs_with_context",
-		Description: "Advanced search that returns matching text with surrounding context. Shows exactly how terms are used in documentation.\n\nWhen to use:\n- Understanding how a term is used in context\n- Getting code examples that use specific features\n- Finding usage patterns\n- Quick answers without reading full docs",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input SearchWithContextInput) (*mcp.CallToolResult, EmptyOutput, error) {
+		Description: "Advanced search that returns the matching passage of each relevant section, keeping paragraphs and code blocks intact. Each passage is labelled 'file#anchor' with its section title.\n\nWhen to use:\n- Understanding how a term is used in context\n- Getting code examples that use specific features\n- Finding usage patterns\n- Quick answers without reading full docs",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input SearchWithContextInput) (*mcp.CallToolResult, SearchOutput, error) {
 		if input.Query == "" {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: "query is required"}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, SearchOutput{}, nil
 		}
 
 		contextLength := 200
 		if input.ContextLength != nil {
 			contextLength = *input.ContextLength
 		}
+		if contextLength < 0 {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "context_length must not be negative"}},
+				IsError: true,
+			}, SearchOutput{}, nil
+		}
 
 		includeExternal := true
 		if input.IncludeExternal != nil {
 			includeExternal = *input.IncludeExternal
 		}
 
-		result, err := s.docManager.SearchWithContext(input.Query, input.Version, contextLength)
+		if result := s.syncingResult(input.Version); result != nil {
+			return result, SearchOutput{}, nil
+		}
+
+		contextResults, err := s.docManager.SearchContext(ctx, input.Query, input.Version, contextLength)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Search failed: %v", err)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, SearchOutput{}, nil
 		}
 
+		result := docs.FormatContextResults(input.Query, input.Version, contextResults)
 		if includeExternal && s.externalManager != nil {
-			externalResults, err := s.externalManager.SearchServicesWithContext(input.Query, nil, contextLength)
+			externalResults, err := s.externalManager.SearchServicesWithContext(ctx, input.Query, nil, contextLength)
 			if err == nil && externalResults != "" {
 				result += "\n\n---\n\n" + externalResults
 			}
@@ -207,58 +259,203 @@ func (s *Server) RegisterDocTools() error {
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, EmptyOutput{}, nil
+		}, newContextSearchOutput(input.Query, input.Version, contextResults), nil
+	})
+
+	// Tool 4b: read_laravel_doc_section
+	mcp.AddTool(s.mcp, &mcp.Tool{
+		Name:        "read_laravel_doc_section",
+		Description: "Reads a single section of a Laravel documentation file by heading text, slug or anchor, with a breadcrumb of its parent headings. Much smaller than reading the whole file.\n\nWhen to use:\n- Following up on a 'file#anchor' search result\n- Reading one topic from a large file like queries.md\n- Keeping responses within context limits\n- Quoting a specific part of the docs",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ReadSectionInput) (*mcp.CallToolResult, SectionOutput, error) {
+		if input.Filename == "" || input.Section == "" {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "filename and section are required"}},
+				IsError: true,
+			}, SectionOutput{}, nil
+		}
+
+		includeSubsections := false
+		if input.IncludeSubsections != nil {
+			includeSubsections = *input.IncludeSubsections
+		}
+
+		version := input.Version
+		if version == "" {
+			version = s.docManager.DefaultVersion()
+		}
+
+		if result := s.syncingResult(version); result != nil {
+			return result, SectionOutput{}, nil
+		}
+
+		section, err := s.docManager.GetSection(ctx, version, input.Filename, input.Section, includeSubsections)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to read section: %v. Use get_doc_structure to list available sections", err)}},
+				IsError: true,
+			}, SectionOutput{}, nil
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: docs.FormatSection(version, input.Filename, section)}},
+		}, newSectionOutput(version, input.Filename, section), nil
 	})
 
 	// Tool 5: get_doc_structure
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "get_doc_structure",
-		Description: "Extracts the table of contents and structure from a documentation file. Shows headers and brief content previews.\n\nWhen to use:\n- Getting an overview of documentation organization\n- Finding specific sections quickly\n- Understanding document layout\n- Navigation planning",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input GetStructureInput) (*mcp.CallToolResult, EmptyOutput, error) {
+		Description: "Extracts the table of contents and structure from a documentation file. Shows headers with their '#anchor' for follow-up section reads.\n\nWhen to use:\n- Getting an overview of documentation organization\n- Finding specific sections quickly\n- Understanding document layout\n- Navigation planning",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input GetStructureInput) (*mcp.CallToolResult, StructureOutput, error) {
 		if input.Filename == "" {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: "filename is required"}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, StructureOutput{}, nil
+		}
+
+		version := input.Version
+		if version == "" {
+			version = s.docManager.DefaultVersion()
 		}
 
-		structure, err := s.docManager.GetStructure(input.Filename, input.Version)
+		if result := s.syncingResult(version); result != nil {
+			return result, StructureOutput{}, nil
+		}
+
+		content, err := s.docManager.ReadDoc(ctx, version, input.Filename)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get structure: %v", err)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, StructureOutput{}, nil
 		}
 
+		sections := docs.ParseSections(content)
+
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: structure}},
-		}, EmptyOutput{}, nil
+			Content: []mcp.Content{&mcp.TextContent{Text: docs.FormatStructure(input.Filename, version, sections)}},
+		}, newStructureOutput(version, input.Filename, sections), nil
 	})
 
 	// Tool 6: browse_docs_by_category
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "browse_docs_by_category",
 		Description: "Discovers documentation files related to specific categories like 'frontend', 'database', or 'authentication'.\n\nWhen to use:\n- Exploring documentation by topic area\n- Finding all related documentation for a feature\n- Learning about a domain (frontend, database, etc.)\n- Discovery of related concepts",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input BrowseCategoryInput) (*mcp.CallToolResult, EmptyOutput, error) {
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input BrowseCategoryInput) (*mcp.CallToolResult, CategoryDocsOutput, error) {
 		if input.Category == "" {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: "category is required"}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, CategoryDocsOutput{}, nil
+		}
+
+		version := input.Version
+		if version == "" {
+			version = s.docManager.DefaultVersion()
+		}
+
+		if result := s.syncingResult(version); result != nil {
+			return result, CategoryDocsOutput{}, nil
 		}
 
-		result, err := s.docManager.BrowseByCategory(input.Category, input.Version)
+		files, err := s.docManager.CategoryFiles(ctx, input.Category, version)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to browse: %v", err)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, CategoryDocsOutput{}, nil
 		}
 
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, EmptyOutput{}, nil
+			Content: []mcp.Content{&mcp.TextContent{Text: docs.FormatCategoryFiles(input.Category, version, files)}},
+		}, CategoryDocsOutput{Category: input.Category, Version: version, Files: files}, nil
+	})
+
+	// Tool 6b: diff_laravel_docs
+	mcp.AddTool(s.mcp, &mcp.Tool{
+		Name:        "diff_laravel_docs",
+		Description: "Compares a documentation file between two Laravel versions section by section. Lists added, removed and changed sections with unified diffs of changed code blocks.\n\nWhen to use:\n- Upgrading an application between Laravel versions\n- Finding what changed in a feature's documentation\n- Reviewing new or removed APIs\n- Checking whether code examples still apply",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input DiffDocsInput) (*mcp.CallToolResult, DiffOutput, error) {
+		if input.Filename == "" || input.FromVersion == "" || input.ToVersion == "" {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "filename, from_version and to_version are required"}},
+				IsError: true,
+			}, DiffOutput{}, nil
+		}
+
+		if result := s.syncingResult(input.FromVersion, input.ToVersion); result != nil {
+			return result, DiffOutput{}, nil
+		}
+
+		diff, err := s.docManager.CompareDoc(ctx, input.Filename, input.FromVersion, input.ToVersion)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to diff docs: %v", err)}},
+				IsError: true,
+			}, DiffOutput{}, nil
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: docs.FormatDocDiff(diff)}},
+		}, newDiffOutput(diff), nil
+	})
+
+	// Tool 6c: get_laravel_upgrade_guide
+	mcp.AddTool(s.mcp, &mcp.Tool{
+		Name:        "get_laravel_upgrade_guide",
+		Description: "Parses Laravel upgrade guides into structured changes with likelihood of impact and affected component, chaining the guides of every intermediate version.\n\nWhen to use:\n- Planning an upgrade across one or more major versions\n- Finding high-impact breaking changes\n- Checking which changes affect a component like queues or Eloquent\n- Building an upgrade checklist",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input UpgradeGuideInput) (*mcp.CallToolResult, UpgradeGuideOutput, error) {
+		if input.FromVersion == "" || input.ToVersion == "" {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "from_version and to_version are required"}},
+				IsError: true,
+			}, UpgradeGuideOutput{}, nil
+		}
+
+		details := true
+		if input.Details != nil {
+			details = *input.Details
+		}
+
+		filter := docs.UpgradeFilter{
+			MinImpact: input.MinImpact,
+			Component: input.Component,
+		}
+
+		if result := s.syncingResult(input.FromVersion, input.ToVersion); result != nil {
+			return result, UpgradeGuideOutput{}, nil
+		}
+
+		plan, err := s.docManager.PlanUpgrade(ctx, input.FromVersion, input.ToVersion, filter)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get upgrade guide: %v", err)}},
+				IsError: true,
+			}, UpgradeGuideOutput{}, nil
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: docs.FormatUpgradePlan(plan, details)}},
+		}, newUpgradeGuideOutput(plan, details), nil
 	})
 
 	return nil
 }
+
+// search runs a documentation search and returns its Markdown and structured
+// results. An empty version searches all versions.
+func (s *Server) search(ctx context.Context, query, version string) (string, SearchOutput, error) {
+	if version == "" {
+		results, err := s.docManager.SearchAllVersions(ctx, query)
+		if err != nil {
+			return "", SearchOutput{}, err
+		}
+		return docs.FormatAllVersionsResults(query, results), newAllVersionsSearchOutput(query, results), nil
+	}
+
+	hits, err := s.docManager.Search(ctx, query, version, 0)
+	if err != nil {
+		return "", SearchOutput{}, err
+	}
+	return docs.FormatSearchResults(query, version, hits), newSearchOutput(query, version, hits), nil
+}
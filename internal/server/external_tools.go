@@ -2,16 +2,25 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/docs"
+	mcperrors "github.com/izzamoe/laravel-mcp-companion-go/internal/errors"
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/external"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/models"
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/updater"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// staleSyncAge is how old a local copy gets before list_laravel_versions calls it stale
+const staleSyncAge = 7 * 24 * time.Hour
+
 // Tool input types for external tools
 type UpdateDocsInput struct {
-	VersionParam string `json:"version_param,omitempty" jsonschema:"Laravel version branch (e.g. '12.x')"`
+	VersionParam string `json:"version_param,omitempty" jsonschema:"Laravel version branch (e.g. '12.x'). Defaults to the default version"`
 	Force        *bool  `json:"force,omitempty" jsonschema:"Force update even if already up to date"`
 }
 
@@ -20,7 +29,7 @@ type DocsInfoInput struct {
 }
 
 type UpdateExternalInput struct {
-	Services []string `json:"services,omitempty" jsonschema:"List of services to update (forge vapor envoyer nova). If None updates all"`
+	Services []string `json:"services,omitempty" jsonschema:"IDs of the services to update as listed by list_laravel_services (e.g. 'forge'). If None updates all"`
 	Force    *bool    `json:"force,omitempty" jsonschema:"Force update even if cache is valid"`
 }
 
@@ -30,7 +39,18 @@ type SearchExternalInput struct {
 }
 
 type ServiceInfoInput struct {
-	Service string `json:"service" jsonschema:"required,Service name (forge vapor envoyer nova)"`
+	Service string `json:"service" jsonschema:"required,Service ID as listed by list_laravel_services (e.g. 'forge')"`
+}
+
+type ListExternalDocsInput struct {
+	Service string `json:"service" jsonschema:"required,Service ID as listed by list_laravel_services (e.g. 'forge')"`
+}
+
+type ReadExternalDocInput struct {
+	Service            string `json:"service" jsonschema:"required,Service ID as listed by list_laravel_services (e.g. 'forge')"`
+	Page               string `json:"page" jsonschema:"required,Page ID URL or title from list_external_laravel_docs (e.g. 'docs-servers')"`
+	Section            string `json:"section,omitempty" jsonschema:"Heading text slug or anchor of a section to read instead of the whole page"`
+	IncludeSubsections *bool  `json:"include_subsections,omitempty" jsonschema:"Include nested subsections below the heading (default: false)"`
 }
 
 // RegisterExternalTools registers update and external resource tools
@@ -38,11 +58,11 @@ func (s *Server) RegisterExternalTools(upd *updater.GitHubUpdater, scraper *exte
 	// Tool 11: update_laravel_docs
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "update_laravel_docs",
-		Description: "Updates documentation from the official Laravel GitHub repository. Ensures access to the latest documentation changes.\n\nWhen to use:\n- Getting the latest documentation\n- Ensuring documentation is up to date\n- After Laravel version release\n- When documentation seems outdated",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input UpdateDocsInput) (*mcp.CallToolResult, EmptyOutput, error) {
+		Description: "Updates documentation from the official Laravel GitHub repository. Only files changed since the last sync are downloaded, and a changelog of added, modified and removed files is returned.\n\nWhen to use:\n- Getting the latest documentation\n- Ensuring documentation is up to date\n- After Laravel version release\n- When documentation seems outdated",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input UpdateDocsInput) (*mcp.CallToolResult, DocsUpdateOutput, error) {
 		version := input.VersionParam
 		if version == "" {
-			version = "12.x"
+			version = s.docManager.DefaultVersion()
 		}
 
 		force := false
@@ -50,216 +70,397 @@ func (s *Server) RegisterExternalTools(upd *updater.GitHubUpdater, scraper *exte
 			force = *input.Force
 		}
 
-		result, err := upd.UpdateDocs(version)
+		report, err := upd.SyncDocs(ctx, version, force, progressNotifier(ctx, request))
 		if err != nil {
 			errMsg := fmt.Sprintf("Update failed: %v", err)
-			if !force {
+			// Forcing the update would not help until a rate limit resets
+			var mcpErr *mcperrors.MCPError
+			rateLimited := errors.As(err, &mcpErr) && mcpErr.Code == mcperrors.ErrGitHubAPI
+			if !force && !rateLimited {
 				errMsg += ". Try with force=true to force update"
 			}
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: errMsg}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, DocsUpdateOutput{}, nil
+		}
+		result := report.Summary()
+
+		// Rebuild the search index so new content is searchable right away
+		if report.Changed() || report.Full {
+			if err := s.docManager.RefreshIndex(ctx, version); err != nil {
+				result += fmt.Sprintf("\n\nWarning: failed to rebuild search index: %v", err)
+			}
+			s.RefreshDocResources(ctx, version)
 		}
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, EmptyOutput{}, nil
+		}, DocsUpdateOutput{
+			Version:        version,
+			PreviousCommit: report.PreviousCommit,
+			Commit:         report.Commit,
+			UpToDate:       report.UpToDate,
+			Added:          report.Added,
+			Modified:       report.Modified,
+			Removed:        report.Removed,
+			Unchanged:      report.Unchanged,
+			Message:        result,
+		}, nil
 	})
 
 	// Tool 12: laravel_docs_info
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "laravel_docs_info",
 		Description: "Provides metadata about documentation versions, including last update times and commit information.\n\nWhen to use:\n- Checking documentation freshness\n- Verifying which version is available\n- Getting documentation statistics\n- Planning documentation updates",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input DocsInfoInput) (*mcp.CallToolResult, EmptyOutput, error) {
-		info, err := s.docManager.GetInfo(input.Version)
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input DocsInfoInput) (*mcp.CallToolResult, DocsInfoOutput, error) {
+		infos, err := s.docManager.VersionInfos(ctx, input.Version)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get info: %v", err)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, DocsInfoOutput{}, nil
 		}
 
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: info}},
-		}, EmptyOutput{}, nil
+			Content: []mcp.Content{&mcp.TextContent{Text: docs.FormatVersionInfos(input.Version, infos)}},
+		}, DocsInfoOutput{Versions: newDocsInfos(infos)}, nil
 	})
+
+	// Tool 12b: list_laravel_versions
+	mcp.AddTool(s.mcp, &mcp.Tool{
+		Name:        "list_laravel_versions",
+		Description: "Lists the Laravel documentation versions offered by the docs source and those downloaded locally, with when each local copy was last synced.\n\nWhen to use:\n- Checking whether a new Laravel release is available\n- Finding which versions can be read offline\n- Spotting stale local documentation\n- Choosing versions to update",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, VersionsOutput, error) {
+		output := s.versionStatuses(ctx, upd)
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: formatVersionStatuses(output)}},
+		}, output, nil
+	})
+}
+
+// versionStatuses merges the versions offered by the docs source with the ones on disk
+func (s *Server) versionStatuses(ctx context.Context, upd *updater.GitHubUpdater) VersionsOutput {
+	var output VersionsOutput
+	statuses := make(map[string]*VersionStatus)
+	status := func(version string) *VersionStatus {
+		if statuses[version] == nil {
+			statuses[version] = &VersionStatus{Version: version, Default: version == s.docManager.DefaultVersion()}
+		}
+		return statuses[version]
+	}
+
+	remote, err := upd.RemoteVersions(ctx)
+	if err != nil {
+		output.RemoteError = err.Error()
+	}
+	for _, version := range remote {
+		status(version).Remote = true
+	}
+
+	infos, _ := s.docManager.VersionInfos(ctx, "")
+	for _, info := range infos {
+		st := status(info.Version)
+		st.Local = true
+		st.FileCount = info.FileCount
+		st.CommitSHA = info.CommitSHA
+		if !info.LastSynced.IsZero() {
+			st.LastSynced = info.LastSynced.Format(time.RFC3339)
+			st.Stale = time.Since(info.LastSynced) > staleSyncAge
+		}
+	}
+
+	versions := make([]string, 0, len(statuses))
+	for version := range statuses {
+		versions = append(versions, version)
+	}
+	models.SortVersions(versions)
+	for _, version := range versions {
+		output.Versions = append(output.Versions, *statuses[version])
+	}
+
+	return output
 }
 
-// RegisterExternalServiceTools registers external Laravel service tools (Tools 13-16)
+// formatVersionStatuses renders the version list as Markdown
+func formatVersionStatuses(output VersionsOutput) string {
+	var result strings.Builder
+	result.WriteString("# Laravel Documentation Versions\n\n")
+	if output.RemoteError != "" {
+		result.WriteString(fmt.Sprintf("Warning: could not list remote versions: %s\n\n", output.RemoteError))
+	}
+
+	for _, v := range output.Versions {
+		title := v.Version
+		if v.Default {
+			title += " (default)"
+		}
+		result.WriteString(fmt.Sprintf("## %s\n", title))
+
+		switch {
+		case v.Local && v.LastSynced != "":
+			synced, _ := time.Parse(time.RFC3339, v.LastSynced)
+			line := fmt.Sprintf("Downloaded: %d files, synced %s ago", v.FileCount, formatAge(time.Since(synced)))
+			if v.Stale {
+				line += " (stale, consider update_laravel_docs)"
+			}
+			result.WriteString(line + "\n")
+		case v.Local:
+			result.WriteString(fmt.Sprintf("Downloaded: %d files, not synced by the updater\n", v.FileCount))
+		default:
+			result.WriteString("Not downloaded. Use update_laravel_docs to fetch it.\n")
+		}
+		if !v.Remote && output.RemoteError == "" {
+			result.WriteString("Not offered by the docs source\n")
+		}
+		result.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(result.String(), "\n")
+}
+
+// formatAge renders a duration in the largest whole unit
+func formatAge(age time.Duration) string {
+	switch {
+	case age >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(age.Hours()/24))
+	case age >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%d minutes", int(age.Minutes()))
+	}
+}
+
+// RegisterExternalServiceTools registers external Laravel service tools (Tools 13-18)
 func (s *Server) RegisterExternalServiceTools(externalManager *external.ExternalManager) {
 	// Tool 13: update_external_laravel_docs
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "update_external_laravel_docs",
-		Description: "Updates documentation for external Laravel services like Forge, Vapor, Envoyer, and Nova.\n\nWhen to use:\n- Getting latest external service docs\n- Setting up deployment workflows\n- Learning about Laravel services\n- Checking service features",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input UpdateExternalInput) (*mcp.CallToolResult, EmptyOutput, error) {
+		Description: "Updates documentation for external Laravel services like Forge, Vapor, Envoyer, Nova and any others in the services config.\n\nWhen to use:\n- Getting latest external service docs\n- Setting up deployment workflows\n- Learning about Laravel services\n- Checking service features",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input UpdateExternalInput) (*mcp.CallToolResult, ExternalUpdateOutput, error) {
 		force := false
 		if input.Force != nil {
 			force = *input.Force
 		}
 
-		result, err := externalManager.UpdateServices(input.Services, force)
+		result, err := externalManager.UpdateServices(ctx, input.Services, force, progressNotifier(ctx, request))
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Update failed: %v", err)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, ExternalUpdateOutput{}, nil
 		}
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, EmptyOutput{}, nil
+		}, ExternalUpdateOutput{Services: input.Services, Message: result}, nil
 	})
 
 	// Tool 14: list_laravel_services
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "list_laravel_services",
 		Description: "Lists all available Laravel services with external documentation support.\n\nWhen to use:\n- Discovering Laravel services\n- Planning service integration\n- Learning about Laravel ecosystem\n- Checking available services",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, EmptyOutput, error) {
-		result := `# Available Laravel Services
-
-## 1. Laravel Forge
-Automated server management and deployment platform for Laravel applications.
-**URL:** https://forge.laravel.com
-
-## 2. Laravel Vapor
-Serverless deployment platform for Laravel on AWS Lambda.
-**URL:** https://vapor.laravel.com
-
-## 3. Laravel Envoyer
-Zero-downtime deployment platform for PHP applications.
-**URL:** https://envoyer.io
-
-## 4. Laravel Nova
-Administration panel for Laravel applications.
-**URL:** https://nova.laravel.com`
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, ServicesOutput, error) {
+		var services []ServiceInfo
+		var result strings.Builder
+		result.WriteString("# Available Laravel Services\n")
+		for i, config := range externalManager.Services() {
+			service := newServiceInfo(config)
+			services = append(services, service)
+			result.WriteString(fmt.Sprintf("\n## %d. %s (`%s`)\n%s\n**URL:** %s\n", i+1, service.Name, service.ID, service.Summary, service.Website))
+		}
 
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, EmptyOutput{}, nil
+			Content: []mcp.Content{&mcp.TextContent{Text: strings.TrimSuffix(result.String(), "\n")}},
+		}, ServicesOutput{Services: services}, nil
 	})
 
 	// Tool 15: search_external_laravel_docs
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "search_external_laravel_docs",
 		Description: "Searches through external Laravel service documentation.\n\nWhen to use:\n- Finding service-specific information\n- Learning about service features\n- Troubleshooting service issues\n- Comparing service capabilities",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input SearchExternalInput) (*mcp.CallToolResult, EmptyOutput, error) {
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input SearchExternalInput) (*mcp.CallToolResult, ExternalSearchOutput, error) {
 		if input.Query == "" {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: "query is required"}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, ExternalSearchOutput{}, nil
 		}
 
-		result, err := externalManager.SearchServices(input.Query, input.Services)
+		matches, err := externalManager.MatchServices(ctx, input.Query, input.Services)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Search failed: %v", err)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, ExternalSearchOutput{}, nil
 		}
 
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: result}},
-		}, EmptyOutput{}, nil
+			Content: []mcp.Content{&mcp.TextContent{Text: external.FormatServiceMatches(input.Query, matches)}},
+		}, ExternalSearchOutput{
+			Query:    input.Query,
+			Services: newServiceMatches(matches),
+		}, nil
 	})
 
 	// Tool 16: get_laravel_service_info
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "get_laravel_service_info",
-		Description: "Provides detailed information about a specific Laravel service.\n\nWhen to use:\n- Learning about a service\n- Understanding service pricing\n- Checking service requirements\n- Planning service adoption",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input ServiceInfoInput) (*mcp.CallToolResult, EmptyOutput, error) {
+		Description: "Provides detailed information about a specific Laravel service, taken from its cached documentation: when it was cached, its sections and the features it documents. Services that were never cached get a short static description.\n\nWhen to use:\n- Learning about a service\n- Understanding service pricing\n- Checking service requirements\n- Planning service adoption",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ServiceInfoInput) (*mcp.CallToolResult, ServiceInfo, error) {
 		if input.Service == "" {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: "service is required"}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, ServiceInfo{}, nil
 		}
 
-		serviceInfo := map[string]string{
-			"forge": `# Laravel Forge
-
-**Type:** Server Management & Deployment Platform
-
-**Description:**
-Laravel Forge is a server management and deployment platform that makes it easy to deploy and manage your Laravel applications on any cloud provider.
-
-**Key Features:**
-- Automated server provisioning
-- Zero-downtime deployments
-- SSL certificate management
-- Database management
-- Queue worker monitoring
-- Scheduled job management
-
-**Website:** https://forge.laravel.com
-**Documentation:** https://forge.laravel.com/docs`,
-
-			"vapor": `# Laravel Vapor
-
-**Type:** Serverless Deployment Platform
-
-**Description:**
-Laravel Vapor is an auto-scaling, serverless deployment platform for Laravel, powered by AWS Lambda.
-
-**Key Features:**
-- Serverless infrastructure
-- Auto-scaling capabilities
-- Global CDN integration
-- Database management
-- Cache and queue services
-- Environment management
-
-**Website:** https://vapor.laravel.com
-**Documentation:** https://docs.vapor.build`,
-
-			"envoyer": `# Laravel Envoyer
-
-**Type:** Zero-Downtime Deployment
-
-**Description:**
-Envoyer is a zero-downtime deployment platform for PHP applications, including Laravel.
-
-**Key Features:**
-- Zero-downtime deployments
-- Health checks
-- Deployment hooks
-- Team collaboration
-- Deployment monitoring
-- Rollback capabilities
+		config, ok := externalManager.Service(input.Service)
+		if !ok {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Service not found: %s. Available services: %s", input.Service, strings.Join(externalManager.ServiceNames(), ", "))}},
+				IsError: true,
+			}, ServiceInfo{}, nil
+		}
+		info := newServiceInfo(config)
+		if overview, err := externalManager.Overview(ctx, input.Service); err == nil {
+			info = withOverview(info, overview)
+		}
 
-**Website:** https://envoyer.io
-**Documentation:** https://envoyer.io/docs`,
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: formatServiceInfo(info)}},
+		}, info, nil
+	})
 
-			"nova": `# Laravel Nova
+	// Tool 17: list_external_laravel_docs
+	mcp.AddTool(s.mcp, &mcp.Tool{
+		Name:        "list_external_laravel_docs",
+		Description: "Lists the cached documentation pages of a Laravel service with their URLs and top-level sections.\n\nWhen to use:\n- Finding the page that covers a service feature\n- Following up on an external search result\n- Choosing what to read with read_external_laravel_doc\n- Checking how much of a service is cached",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ListExternalDocsInput) (*mcp.CallToolResult, ExternalPagesOutput, error) {
+		if input.Service == "" {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "service is required"}},
+				IsError: true,
+			}, ExternalPagesOutput{}, nil
+		}
 
-**Type:** Administration Panel
+		pages, err := externalManager.ListPages(ctx, input.Service)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list pages: %v. Use update_external_laravel_docs to fetch the documentation first", err)}},
+				IsError: true,
+			}, ExternalPagesOutput{}, nil
+		}
 
-**Description:**
-Laravel Nova is a beautifully-designed administration panel for Laravel applications.
+		config, _ := externalManager.Service(input.Service)
+		output := ExternalPagesOutput{Service: input.Service, Pages: newExternalPages(pages)}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: formatExternalPages(config.Name, output)}},
+		}, output, nil
+	})
 
-**Key Features:**
-- Resource management
-- Custom metrics and cards
-- Action execution
-- Advanced filters
-- Relationship management
-- Authorization
+	// Tool 18: read_external_laravel_doc
+	mcp.AddTool(s.mcp, &mcp.Tool{
+		Name:        "read_external_laravel_doc",
+		Description: "Reads a cached documentation page of a Laravel service, or a single section of it by heading text, slug or anchor.\n\nWhen to use:\n- Reading a page found with list_external_laravel_docs\n- Following up on an external search result\n- Quoting service documentation\n- Keeping responses within context limits",
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input ReadExternalDocInput) (*mcp.CallToolResult, ExternalDocOutput, error) {
+		if input.Service == "" || input.Page == "" {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "service and page are required"}},
+				IsError: true,
+			}, ExternalDocOutput{}, nil
+		}
 
-**Website:** https://nova.laravel.com
-**Documentation:** https://nova.laravel.com/docs`,
+		includeSubsections := false
+		if input.IncludeSubsections != nil {
+			includeSubsections = *input.IncludeSubsections
 		}
 
-		info, ok := serviceInfo[input.Service]
-		if !ok {
+		page, err := externalManager.ReadPage(ctx, input.Service, input.Page, input.Section, includeSubsections)
+		if err != nil {
 			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Service not found: %s. Available services: forge, vapor, envoyer, nova", input.Service)}},
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to read page: %v. Use list_external_laravel_docs to list available pages and sections", err)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, ExternalDocOutput{}, nil
 		}
 
+		output := newExternalDoc(page)
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: info}},
-		}, EmptyOutput{}, nil
+			Content: []mcp.Content{&mcp.TextContent{Text: formatExternalDoc(output)}},
+		}, output, nil
 	})
 }
+
+// formatExternalPages renders the cached pages of a service as Markdown
+func formatExternalPages(name string, output ExternalPagesOutput) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# %s Documentation\n\n", name))
+	result.WriteString(fmt.Sprintf("%d cached pages. Read one with read_external_laravel_doc using its ID.\n\n", len(output.Pages)))
+	for _, page := range output.Pages {
+		result.WriteString(fmt.Sprintf("- **%s** (`%s`) - %s\n", page.Title, page.ID, page.URL))
+		for _, heading := range page.Headings {
+			// Deeper headings are listed by reading the page
+			if heading.Level == 2 {
+				result.WriteString(fmt.Sprintf("  - %s (#%s)\n", heading.Title, heading.Anchor))
+			}
+		}
+	}
+
+	return strings.TrimSuffix(result.String(), "\n")
+}
+
+// formatExternalDoc renders a cached page, or a section of one, with its source
+func formatExternalDoc(output ExternalDocOutput) string {
+	var result strings.Builder
+	source := fmt.Sprintf("[%s](%s)", output.Title, output.URL)
+	if output.Anchor != "" {
+		source = fmt.Sprintf("[%s](%s#%s)", output.Title, output.URL, output.Anchor)
+	}
+	result.WriteString(fmt.Sprintf("**Source:** %s (%s/%s)\n", source, output.Service, output.Page))
+	if len(output.Breadcrumb) > 0 {
+		result.WriteString(fmt.Sprintf("**Breadcrumb:** %s > %s\n", strings.Join(output.Breadcrumb, " > "), output.Section))
+	}
+	result.WriteString("\n")
+	result.WriteString(output.Content)
+	return result.String()
+}
+
+// formatServiceInfo renders a service as Markdown
+func formatServiceInfo(service ServiceInfo) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("# %s\n\n", service.Name))
+	if service.Type != "" {
+		output.WriteString(fmt.Sprintf("**Type:** %s\n\n", service.Type))
+	}
+	if service.Description != "" {
+		output.WriteString(fmt.Sprintf("**Description:**\n%s\n\n", service.Description))
+	}
+	if len(service.Features) > 0 {
+		output.WriteString("**Key Features:**\n")
+		for _, feature := range service.Features {
+			output.WriteString(fmt.Sprintf("- %s\n", feature))
+		}
+		output.WriteString("\n")
+	}
+	if len(service.Sections) > 0 {
+		output.WriteString("**Documentation Sections:**\n")
+		for _, section := range service.Sections {
+			output.WriteString(fmt.Sprintf("- %s\n", section))
+		}
+		output.WriteString("\n")
+	}
+	output.WriteString(fmt.Sprintf("**Website:** %s\n", service.Website))
+	output.WriteString(fmt.Sprintf("**Documentation:** %s", service.Documentation))
+
+	switch {
+	case service.Source != "docs":
+		output.WriteString(fmt.Sprintf("\n\n_Static description. Use update_external_laravel_docs with %s to describe the service from its current documentation._", service.ID))
+	case service.CachedAt != "":
+		cached, _ := time.Parse(time.RFC3339, service.CachedAt)
+		output.WriteString(fmt.Sprintf("\n\n_From %d cached pages, updated %s ago. Read them with list_external_laravel_docs._", service.Pages, formatAge(time.Since(cached))))
+	default:
+		output.WriteString(fmt.Sprintf("\n\n_From %d cached pages. Read them with list_external_laravel_docs._", service.Pages))
+	}
+	return output.String()
+}
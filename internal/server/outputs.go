@@ -0,0 +1,516 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/docs"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/external"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/models"
+)
+
+// Tool output types, returned as structured content alongside the Markdown text.
+// Slices are omitempty because a nil slice would not match the generated array schema.
+
+// DocsListOutput lists the documentation files of a version
+type DocsListOutput struct {
+	Version string   `json:"version"`
+	Files   []string `json:"files,omitempty"`
+}
+
+// DocContentOutput holds a documentation file
+type DocContentOutput struct {
+	Version  string `json:"version"`
+	Filename string `json:"filename"`
+	Content  string `json:"content"`
+}
+
+// SearchResult is one matching documentation section
+type SearchResult struct {
+	Version  string   `json:"version"`
+	File     string   `json:"file"`
+	Section  string   `json:"section" jsonschema:"Title of the matching section"`
+	Anchor   string   `json:"anchor" jsonschema:"Heading anchor, usable with read_laravel_doc_section"`
+	Score    float64  `json:"score" jsonschema:"Relevance score, higher is better"`
+	Snippet  string   `json:"snippet,omitempty" jsonschema:"Passage around the match"`
+	Versions []string `json:"versions,omitempty" jsonschema:"Every version containing this exact section text, newest first"`
+}
+
+// SearchOutput holds the ranked results of a documentation search
+type SearchOutput struct {
+	Query   string         `json:"query"`
+	Version string         `json:"version,omitempty" jsonschema:"Searched version, empty when all versions were searched"`
+	Results []SearchResult `json:"results,omitempty"`
+}
+
+// SectionOutput holds a single documentation section
+type SectionOutput struct {
+	Version    string   `json:"version"`
+	Filename   string   `json:"filename"`
+	Title      string   `json:"title"`
+	Anchor     string   `json:"anchor"`
+	Level      int      `json:"level"`
+	Breadcrumb []string `json:"breadcrumb,omitempty" jsonschema:"Titles of the parent headings, outermost first"`
+	Content    string   `json:"content"`
+}
+
+// Heading is one entry of a file's table of contents
+type Heading struct {
+	Title  string `json:"title"`
+	Level  int    `json:"level"`
+	Anchor string `json:"anchor"`
+}
+
+// StructureOutput holds the table of contents of a documentation file
+type StructureOutput struct {
+	Version  string    `json:"version"`
+	Filename string    `json:"filename"`
+	Headings []Heading `json:"headings,omitempty"`
+}
+
+// CategoryDocsOutput lists the documentation files of a category
+type CategoryDocsOutput struct {
+	Category string   `json:"category"`
+	Version  string   `json:"version"`
+	Files    []string `json:"files,omitempty"`
+}
+
+// SectionDiff describes how one section differs between two versions
+type SectionDiff struct {
+	Title        string   `json:"title"`
+	Anchor       string   `json:"anchor"`
+	Level        int      `json:"level"`
+	ProseChanged bool     `json:"prose_changed,omitempty"`
+	CodeDiffs    []string `json:"code_diffs,omitempty" jsonschema:"Unified diffs of changed code blocks"`
+}
+
+// DiffOutput is a section-aware comparison of a file between two versions
+type DiffOutput struct {
+	Filename    string        `json:"filename"`
+	FromVersion string        `json:"from_version"`
+	ToVersion   string        `json:"to_version"`
+	FromMissing bool          `json:"from_missing,omitempty" jsonschema:"The file does not exist in from_version"`
+	ToMissing   bool          `json:"to_missing,omitempty" jsonschema:"The file does not exist in to_version"`
+	Added       []SectionDiff `json:"added,omitempty"`
+	Removed     []SectionDiff `json:"removed,omitempty"`
+	Changed     []SectionDiff `json:"changed,omitempty"`
+	Unchanged   int           `json:"unchanged"`
+}
+
+// UpgradeChange is a single change described in an upgrade guide
+type UpgradeChange struct {
+	TargetVersion string `json:"target_version"`
+	Component     string `json:"component,omitempty"`
+	Title         string `json:"title"`
+	Anchor        string `json:"anchor"`
+	Impact        string `json:"impact" jsonschema:"Likelihood of impact: high medium low 'very low' or optional"`
+	Content       string `json:"content,omitempty"`
+}
+
+// UpgradeGuideOutput holds the upgrade changes between two versions
+type UpgradeGuideOutput struct {
+	FromVersion   string          `json:"from_version"`
+	ToVersion     string          `json:"to_version"`
+	MissingGuides []string        `json:"missing_guides,omitempty" jsonschema:"Versions whose upgrade guide is not available locally"`
+	Changes       []UpgradeChange `json:"changes,omitempty"`
+}
+
+// PackageInfo describes a package from the catalog
+type PackageInfo struct {
+	Name              string   `json:"name"`
+	ComposerName      string   `json:"composer_name"`
+	Description       string   `json:"description"`
+	UseCases          []string `json:"use_cases,omitempty"`
+	Alternatives      []string `json:"alternatives,omitempty"`
+	Tags              []string `json:"tags,omitempty"`
+	MinLaravelVersion string   `json:"min_laravel_version,omitempty"`
+	PopularityScore   int      `json:"popularity_score"`
+	Maintained        bool     `json:"maintained"`
+	Install           string   `json:"install" jsonschema:"Command that installs the package"`
+}
+
+// PackageListOutput holds the packages recommended for a use case
+type PackageListOutput struct {
+	UseCase  string        `json:"use_case"`
+	Packages []PackageInfo `json:"packages,omitempty"`
+}
+
+// PackageCategoryOutput holds the packages of a catalog category
+type PackageCategoryOutput struct {
+	Category            string        `json:"category"`
+	Description         string        `json:"description,omitempty"`
+	Packages            []PackageInfo `json:"packages,omitempty"`
+	AvailableCategories []string      `json:"available_categories,omitempty" jsonschema:"Known categories, listed when the category was not found"`
+}
+
+// PackageFeaturesOutput holds the features of a package
+type PackageFeaturesOutput struct {
+	Name         string   `json:"name"`
+	ComposerName string   `json:"composer_name"`
+	Features     []string `json:"features,omitempty"`
+	Alternatives []string `json:"alternatives,omitempty"`
+}
+
+// DocsUpdateOutput reports the result of a documentation update
+type DocsUpdateOutput struct {
+	Version        string   `json:"version"`
+	PreviousCommit string   `json:"previous_commit,omitempty"`
+	Commit         string   `json:"commit"`
+	UpToDate       bool     `json:"up_to_date,omitempty"`
+	Added          []string `json:"added,omitempty"`
+	Modified       []string `json:"modified,omitempty"`
+	Removed        []string `json:"removed,omitempty"`
+	Unchanged      int      `json:"unchanged"`
+	Message        string   `json:"message"`
+}
+
+// DocsInfo describes the documentation stored for one version
+type DocsInfo struct {
+	Version     string `json:"version"`
+	LastUpdated string `json:"last_updated" jsonschema:"RFC 3339 time of the last change on disk"`
+	FileCount   int    `json:"file_count"`
+	CommitSHA   string `json:"commit_sha,omitempty"`
+}
+
+// DocsInfoOutput holds metadata about the stored documentation versions
+type DocsInfoOutput struct {
+	Versions []DocsInfo `json:"versions,omitempty"`
+}
+
+// VersionStatus describes where a Laravel version is available and how fresh its local copy is
+type VersionStatus struct {
+	Version    string `json:"version"`
+	Default    bool   `json:"default,omitempty" jsonschema:"Used when a tool is called without a version"`
+	Remote     bool   `json:"remote" jsonschema:"The docs source offers this version"`
+	Local      bool   `json:"local" jsonschema:"The version is downloaded"`
+	FileCount  int    `json:"file_count,omitempty"`
+	CommitSHA  string `json:"commit_sha,omitempty"`
+	LastSynced string `json:"last_synced,omitempty" jsonschema:"RFC 3339 time of the last sync by the updater"`
+	Stale      bool   `json:"stale,omitempty" jsonschema:"The last sync is more than a week old"`
+}
+
+// VersionsOutput lists the known Laravel documentation versions, newest first
+type VersionsOutput struct {
+	Versions    []VersionStatus `json:"versions,omitempty"`
+	RemoteError string          `json:"remote_error,omitempty" jsonschema:"Why the docs source could not be asked for its versions"`
+}
+
+// ExternalUpdateOutput reports the result of an external documentation update
+type ExternalUpdateOutput struct {
+	Services []string `json:"services,omitempty" jsonschema:"Services that were requested, empty for all"`
+	Message  string   `json:"message"`
+}
+
+// ServiceInfo describes a Laravel service
+type ServiceInfo struct {
+	ID            string   `json:"id" jsonschema:"Identifier used by the other service tools (e.g. 'forge')"`
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Summary       string   `json:"summary"`
+	Description   string   `json:"description"`
+	Features      []string `json:"features,omitempty"`
+	Website       string   `json:"website"`
+	Documentation string   `json:"documentation"`
+	Source        string   `json:"source" jsonschema:"'docs' when description and features come from the cached documentation, 'registry' when nothing is cached"`
+	CachedAt      string   `json:"cached_at,omitempty" jsonschema:"RFC 3339 time the documentation was cached"`
+	Pages         int      `json:"pages,omitempty" jsonschema:"Number of cached documentation pages"`
+	Sections      []string `json:"sections,omitempty" jsonschema:"Titles of the cached documentation pages"`
+}
+
+// ServicesOutput lists the Laravel services
+type ServicesOutput struct {
+	Services []ServiceInfo `json:"services,omitempty"`
+}
+
+// ServiceMatch is how often a query occurs in one service's documentation
+type ServiceMatch struct {
+	Service string `json:"service"`
+	Name    string `json:"name"`
+	Matches int    `json:"matches"`
+}
+
+// ExternalSearchOutput holds the services matching a query
+type ExternalSearchOutput struct {
+	Query    string         `json:"query"`
+	Services []ServiceMatch `json:"services,omitempty"`
+}
+
+// ExternalPage describes a cached documentation page of a service
+type ExternalPage struct {
+	ID       string    `json:"id" jsonschema:"Identifier used by read_external_laravel_doc"`
+	Title    string    `json:"title"`
+	URL      string    `json:"url"`
+	Size     int       `json:"size" jsonschema:"Length of the page in bytes"`
+	Headings []Heading `json:"headings,omitempty"`
+}
+
+// ExternalPagesOutput lists the cached documentation pages of a service
+type ExternalPagesOutput struct {
+	Service string         `json:"service"`
+	Pages   []ExternalPage `json:"pages,omitempty"`
+}
+
+// ExternalDocOutput holds a cached page of a service, or a single section of it
+type ExternalDocOutput struct {
+	Service    string   `json:"service"`
+	Page       string   `json:"page" jsonschema:"Page ID"`
+	Title      string   `json:"title" jsonschema:"Page title"`
+	URL        string   `json:"url"`
+	Section    string   `json:"section,omitempty" jsonschema:"Title of the section read, empty for the whole page"`
+	Anchor     string   `json:"anchor,omitempty"`
+	Breadcrumb []string `json:"breadcrumb,omitempty" jsonschema:"Titles of the parent headings, outermost first"`
+	Content    string   `json:"content"`
+}
+
+// newPackageInfo converts a catalog package
+func newPackageInfo(pkg models.Package) PackageInfo {
+	return PackageInfo{
+		Name:              pkg.Name,
+		ComposerName:      pkg.ComposerName,
+		Description:       pkg.Description,
+		UseCases:          pkg.UseCase,
+		Alternatives:      pkg.Alternatives,
+		Tags:              pkg.Tags,
+		MinLaravelVersion: pkg.MinLaravelVersion,
+		PopularityScore:   pkg.PopularityScore,
+		Maintained:        pkg.Maintained,
+		Install:           fmt.Sprintf("composer require %s", pkg.ComposerName),
+	}
+}
+
+// newPackageInfos converts a list of catalog packages
+func newPackageInfos(pkgs []models.Package) []PackageInfo {
+	var infos []PackageInfo
+	for _, pkg := range pkgs {
+		infos = append(infos, newPackageInfo(pkg))
+	}
+	return infos
+}
+
+// newSearchResult converts a search hit
+func newSearchResult(hit docs.SearchHit) SearchResult {
+	return SearchResult{
+		Version: hit.Version,
+		File:    hit.File,
+		Section: hit.Section,
+		Anchor:  hit.Anchor,
+		Score:   hit.Score,
+	}
+}
+
+// newSearchOutput converts the hits of a single-version search
+func newSearchOutput(query, version string, hits []docs.SearchHit) SearchOutput {
+	output := SearchOutput{Query: query, Version: version}
+	for i, hit := range hits {
+		if i >= maxSearchResults {
+			break
+		}
+		output.Results = append(output.Results, newSearchResult(hit))
+	}
+	return output
+}
+
+// newAllVersionsSearchOutput converts the results of an all-versions search
+func newAllVersionsSearchOutput(query string, results []docs.VersionResults) SearchOutput {
+	output := SearchOutput{Query: query}
+	for _, vr := range results {
+		for i, p := range vr.Passages {
+			if i >= maxSearchResultsPerVersion {
+				break
+			}
+			result := newSearchResult(p.Hit)
+			result.Versions = p.Versions
+			output.Results = append(output.Results, result)
+		}
+	}
+	return output
+}
+
+// newContextSearchOutput converts the results of a search with context
+func newContextSearchOutput(query, version string, results []docs.ContextResults) SearchOutput {
+	output := SearchOutput{Query: query, Version: version}
+	for _, cr := range results {
+		for _, match := range cr.Matches {
+			result := newSearchResult(match.Hit)
+			result.Snippet = match.Text
+			// A single-version search says nothing about other versions
+			if version == "" {
+				result.Versions = match.Versions
+			}
+			output.Results = append(output.Results, result)
+		}
+	}
+	return output
+}
+
+// newSectionOutput converts a documentation section
+func newSectionOutput(version, filename string, section *docs.SectionContent) SectionOutput {
+	return SectionOutput{
+		Version:    version,
+		Filename:   filename,
+		Title:      section.Section.Title,
+		Anchor:     section.Section.Anchor,
+		Level:      section.Section.Level,
+		Breadcrumb: section.Breadcrumb,
+		Content:    section.Content,
+	}
+}
+
+// newStructureOutput converts the headings of a documentation file
+func newStructureOutput(version, filename string, sections []docs.Section) StructureOutput {
+	output := StructureOutput{Version: version, Filename: filename}
+	for _, sec := range sections {
+		if sec.Level > 0 {
+			output.Headings = append(output.Headings, Heading{Title: sec.Title, Level: sec.Level, Anchor: sec.Anchor})
+		}
+	}
+	return output
+}
+
+// newDiffOutput converts a section-aware diff
+func newDiffOutput(diff *docs.DocDiff) DiffOutput {
+	return DiffOutput{
+		Filename:    diff.Filename,
+		FromVersion: diff.FromVersion,
+		ToVersion:   diff.ToVersion,
+		FromMissing: diff.FromMissing,
+		ToMissing:   diff.ToMissing,
+		Added:       newSectionDiffs(diff.Added),
+		Removed:     newSectionDiffs(diff.Removed),
+		Changed:     newSectionDiffs(diff.Changed),
+		Unchanged:   diff.Unchanged,
+	}
+}
+
+// newUpgradeGuideOutput converts an upgrade plan, leaving out the text of each
+// change unless details is set
+func newUpgradeGuideOutput(plan *docs.UpgradePlan, details bool) UpgradeGuideOutput {
+	output := UpgradeGuideOutput{
+		FromVersion:   plan.FromVersion,
+		ToVersion:     plan.ToVersion,
+		MissingGuides: plan.Missing,
+	}
+	for _, item := range plan.Items {
+		change := UpgradeChange{
+			TargetVersion: item.TargetVersion,
+			Component:     item.Component,
+			Title:         item.Title,
+			Anchor:        item.Anchor,
+			Impact:        item.Impact,
+		}
+		if details {
+			change.Content = item.Content
+		}
+		output.Changes = append(output.Changes, change)
+	}
+	return output
+}
+
+// newSectionDiffs converts section changes
+func newSectionDiffs(changes []docs.SectionChange) []SectionDiff {
+	var diffs []SectionDiff
+	for _, c := range changes {
+		diffs = append(diffs, SectionDiff{
+			Title:        c.Title,
+			Anchor:       c.Anchor,
+			Level:        c.Level,
+			ProseChanged: c.ProseChanged,
+			CodeDiffs:    c.CodeDiffs,
+		})
+	}
+	return diffs
+}
+
+// newDocsInfos converts version metadata
+func newDocsInfos(infos []docs.VersionInfo) []DocsInfo {
+	var out []DocsInfo
+	for _, info := range infos {
+		out = append(out, DocsInfo{
+			Version:     info.Version,
+			LastUpdated: info.LastUpdated.Format(time.RFC3339),
+			FileCount:   info.FileCount,
+			CommitSHA:   info.CommitSHA,
+		})
+	}
+	return out
+}
+
+// newServiceMatches converts external search matches
+func newServiceMatches(matches []external.ServiceMatch) []ServiceMatch {
+	var out []ServiceMatch
+	for _, m := range matches {
+		out = append(out, ServiceMatch{Service: m.Service, Name: m.Name, Matches: m.Matches})
+	}
+	return out
+}
+
+// newServiceInfo converts a service from the registry
+func newServiceInfo(config external.ServiceConfig) ServiceInfo {
+	info := ServiceInfo{
+		ID:            config.ID,
+		Name:          config.Name,
+		Type:          config.Type,
+		Summary:       config.Summary,
+		Description:   config.Description,
+		Features:      config.Features,
+		Website:       config.Website,
+		Documentation: config.URL,
+		Source:        "registry",
+	}
+	// Services only need one of the two descriptions in the registry
+	if info.Summary == "" {
+		info.Summary = info.Description
+	}
+	if info.Description == "" {
+		info.Description = info.Summary
+	}
+	return info
+}
+
+// withOverview replaces the registry description and features of a service
+// with what its cached documentation says
+func withOverview(info ServiceInfo, overview *external.ServiceOverview) ServiceInfo {
+	info.Source = "docs"
+	info.Pages = overview.Metadata.Pages
+	info.Sections = overview.Sections
+	if !overview.Metadata.CachedAt.IsZero() {
+		info.CachedAt = overview.Metadata.CachedAt.Format(time.RFC3339)
+	}
+	if overview.Intro != "" {
+		info.Description = overview.Intro
+	}
+	if len(overview.Features) > 0 {
+		info.Features = overview.Features
+	}
+	return info
+}
+
+// newExternalPages converts the index of a service's cached pages
+func newExternalPages(pages []external.PageInfo) []ExternalPage {
+	var out []ExternalPage
+	for _, page := range pages {
+		ep := ExternalPage{ID: page.ID, Title: page.Title, URL: page.URL, Size: page.Size}
+		for _, sec := range page.Sections {
+			ep.Headings = append(ep.Headings, Heading{Title: sec.Title, Level: sec.Level, Anchor: sec.Anchor})
+		}
+		out = append(out, ep)
+	}
+	return out
+}
+
+// newExternalDoc converts a cached page or section
+func newExternalDoc(page *external.PageContent) ExternalDocOutput {
+	out := ExternalDocOutput{
+		Service: page.Service,
+		Page:    page.Page.ID,
+		Title:   page.Page.Title,
+		URL:     page.Page.URL,
+		Content: page.Content,
+	}
+	if page.Section != nil {
+		out.Section = page.Section.Section.Title
+		out.Anchor = page.Section.Section.Anchor
+		out.Breadcrumb = page.Section.Breadcrumb
+	}
+	return out
+}
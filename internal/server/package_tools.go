@@ -32,15 +32,21 @@ func (s *Server) RegisterPackageTools(catalog *packages.Catalog) {
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "get_laravel_package_recommendations",
 		Description: "Intelligently recommends Laravel packages based on described use cases or implementation needs.\n\nWhen to use:\n- Starting a new feature and need package suggestions\n- Looking for solutions to specific problems\n- Comparing available options for a use case\n- Discovering well-maintained packages",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input RecommendPackageInput) (*mcp.CallToolResult, EmptyOutput, error) {
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input RecommendPackageInput) (*mcp.CallToolResult, PackageListOutput, error) {
 		if input.UseCase == "" {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: "use_case is required"}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, PackageListOutput{}, nil
 		}
 
-		recommendations := catalog.Recommend(input.UseCase, 5)
+		recommendations, err := catalog.Recommend(ctx, input.UseCase, 5)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get recommendations: %v", err)}},
+				IsError: true,
+			}, PackageListOutput{}, nil
+		}
 
 		var output strings.Builder
 		output.WriteString(fmt.Sprintf("# Laravel Packages for: %s\n\n", input.UseCase))
@@ -69,27 +75,27 @@ func (s *Server) RegisterPackageTools(catalog *packages.Catalog) {
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: output.String()}},
-		}, EmptyOutput{}, nil
+		}, PackageListOutput{UseCase: input.UseCase, Packages: newPackageInfos(recommendations)}, nil
 	})
 
 	// Tool 8: get_laravel_package_info
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "get_laravel_package_info",
 		Description: "Provides comprehensive details about a specific Laravel package including installation and use cases.\n\nWhen to use:\n- Learning about a specific package\n- Getting installation instructions\n- Understanding package capabilities\n- Checking maintenance status",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input PackageInfoInput) (*mcp.CallToolResult, EmptyOutput, error) {
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input PackageInfoInput) (*mcp.CallToolResult, PackageInfo, error) {
 		if input.PackageName == "" {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: "package_name is required"}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, PackageInfo{}, nil
 		}
 
-		pkg, err := catalog.GetPackage(input.PackageName)
+		pkg, err := catalog.GetPackage(ctx, input.PackageName)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Package not found: %s", input.PackageName)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, PackageInfo{}, nil
 		}
 
 		formatted, err := packages.FormatPackageDetails(pkg)
@@ -97,24 +103,24 @@ func (s *Server) RegisterPackageTools(catalog *packages.Catalog) {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to format package: %v", err)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, PackageInfo{}, nil
 		}
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: formatted}},
-		}, EmptyOutput{}, nil
+		}, newPackageInfo(*pkg), nil
 	})
 
 	// Tool 9: get_laravel_package_categories
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "get_laravel_package_categories",
 		Description: "Lists all packages within a specific functional category.\n\nWhen to use:\n- Exploring packages by category\n- Finding all authentication/payment/testing packages\n- Discovering options in a domain\n- Browsing available solutions",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input PackageCategoryInput) (*mcp.CallToolResult, EmptyOutput, error) {
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input PackageCategoryInput) (*mcp.CallToolResult, PackageCategoryOutput, error) {
 		if input.Category == "" {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: "category is required"}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, PackageCategoryOutput{}, nil
 		}
 
 		cat, err := catalog.GetCategory(input.Category)
@@ -126,7 +132,7 @@ func (s *Server) RegisterPackageTools(catalog *packages.Catalog) {
 					input.Category,
 					strings.Join(availableCategories, ", "),
 				)}},
-			}, EmptyOutput{}, nil
+			}, PackageCategoryOutput{Category: input.Category, AvailableCategories: availableCategories}, nil
 		}
 
 		formatted, err := packages.FormatCategoryPackages(cat)
@@ -134,35 +140,39 @@ func (s *Server) RegisterPackageTools(catalog *packages.Catalog) {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to format category: %v", err)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, PackageCategoryOutput{}, nil
 		}
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: formatted}},
-		}, EmptyOutput{}, nil
+		}, PackageCategoryOutput{
+			Category:    input.Category,
+			Description: cat.Description,
+			Packages:    newPackageInfos(cat.Packages),
+		}, nil
 	})
 
 	// Tool 10: get_features_for_laravel_package
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "get_features_for_laravel_package",
 		Description: "Details common implementation features and patterns for a specific package.\n\nWhen to use:\n- Understanding what a package can do\n- Planning implementation\n- Comparing package capabilities\n- Learning package features",
-	}, func(ctx context.Context, request *mcp.CallToolRequest, input PackageFeaturesInput) (*mcp.CallToolResult, EmptyOutput, error) {
+	}, func(ctx context.Context, request *mcp.CallToolRequest, input PackageFeaturesInput) (*mcp.CallToolResult, PackageFeaturesOutput, error) {
 		if input.Package == "" {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: "package is required"}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, PackageFeaturesOutput{}, nil
 		}
 
-		pkg, err := catalog.GetPackage(input.Package)
+		pkg, err := catalog.GetPackage(ctx, input.Package)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Package not found: %s", input.Package)}},
 				IsError: true,
-			}, EmptyOutput{}, nil
+			}, PackageFeaturesOutput{}, nil
 		}
 
-		features := catalog.GetFeatures(input.Package)
+		features := catalog.GetFeatures(ctx, input.Package)
 
 		var output strings.Builder
 		output.WriteString(fmt.Sprintf("# Features: %s\n\n", pkg.Name))
@@ -187,6 +197,11 @@ func (s *Server) RegisterPackageTools(catalog *packages.Catalog) {
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: output.String()}},
-		}, EmptyOutput{}, nil
+		}, PackageFeaturesOutput{
+			Name:         pkg.Name,
+			ComposerName: pkg.ComposerName,
+			Features:     features,
+			Alternatives: pkg.Alternatives,
+		}, nil
 	})
 }
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/docs"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/packages"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// maxPromptSections limits how many doc sections a prompt embeds
+	maxPromptSections = 3
+
+	// maxPromptUpgradeItems limits how many upgrade items a prompt embeds
+	maxPromptUpgradeItems = 25
+
+	// maxPromptPackages limits how many catalog entries a prompt embeds
+	maxPromptPackages = 3
+
+	// packagesURIScheme is the scheme of embedded package catalog entries
+	packagesURIScheme = "laravel-packages"
+)
+
+// RegisterPrompts registers the workflow prompts
+func (s *Server) RegisterPrompts(catalog *packages.Catalog) {
+	// Prompt 1: explain_laravel_feature
+	s.mcp.AddPrompt(&mcp.Prompt{
+		Name:        "explain_laravel_feature",
+		Title:       "Explain a Laravel feature",
+		Description: "Explains a Laravel feature for a specific version using the most relevant documentation sections.",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "feature", Description: "The feature to explain (e.g. 'job batching')", Required: true},
+			{Name: "version", Description: "Laravel version (e.g. '12.x'). Defaults to the server's default version"},
+		},
+	}, s.explainFeaturePrompt)
+
+	// Prompt 2: plan_laravel_upgrade
+	s.mcp.AddPrompt(&mcp.Prompt{
+		Name:        "plan_laravel_upgrade",
+		Title:       "Plan a Laravel upgrade",
+		Description: "Plans an upgrade between two Laravel versions from the chained upgrade guides.",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "from_version", Description: "Current Laravel version (e.g. '10.x')", Required: true},
+			{Name: "to_version", Description: "Target Laravel version (e.g. '12.x')", Required: true},
+			{Name: "component", Description: "Only plan changes for this component (e.g. 'queues')"},
+		},
+	}, s.planUpgradePrompt)
+
+	// Prompt 3: choose_laravel_package
+	s.mcp.AddPrompt(&mcp.Prompt{
+		Name:        "choose_laravel_package",
+		Title:       "Choose a Laravel package",
+		Description: "Compares catalog packages for a use case and recommends one.",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "use_case", Description: "What the package should do (e.g. 'subscription billing')", Required: true},
+			{Name: "version", Description: "Laravel version the project uses (e.g. '12.x')"},
+		},
+	}, func(ctx context.Context, request *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return s.choosePackagePrompt(ctx, catalog, request)
+	})
+}
+
+// explainFeaturePrompt embeds the top documentation sections for a feature
+func (s *Server) explainFeaturePrompt(ctx context.Context, request *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	feature := request.Params.Arguments["feature"]
+	if feature == "" {
+		return nil, fmt.Errorf("feature is required")
+	}
+	version := request.Params.Arguments["version"]
+
+	hits, err := s.docManager.Search(ctx, feature, version, maxPromptSections)
+	if err != nil {
+		return nil, err
+	}
+	if version == "" && len(hits) > 0 {
+		version = hits[0].Version
+	}
+
+	var instructions strings.Builder
+	instructions.WriteString(fmt.Sprintf("Explain the Laravel feature \"%s\"", feature))
+	if version != "" {
+		instructions.WriteString(fmt.Sprintf(" as it works in Laravel %s", version))
+	}
+	instructions.WriteString(". Cover what it is for, how to use it with a short code example, and any caveats. ")
+	if len(hits) == 0 {
+		instructions.WriteString("No matching documentation sections were found; say so and answer from general knowledge.")
+	} else {
+		instructions.WriteString("Base the explanation on the documentation sections attached below and cite them by file and heading.")
+	}
+
+	messages := []*mcp.PromptMessage{userText(instructions.String())}
+	for _, hit := range hits {
+		section, err := s.docManager.GetSection(ctx, hit.Version, hit.File, hit.Anchor, true)
+		if err != nil {
+			continue
+		}
+		messages = append(messages, embeddedDoc(docURI(hit.Version, hit.File, hit.Anchor), section.Content))
+	}
+
+	return &mcp.GetPromptResult{
+		Description: fmt.Sprintf("Explain %s using %d documentation sections", feature, len(messages)-1),
+		Messages:    messages,
+	}, nil
+}
+
+// planUpgradePrompt embeds the upgrade guide items between two versions
+func (s *Server) planUpgradePrompt(ctx context.Context, request *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := request.Params.Arguments
+	fromVersion, toVersion := args["from_version"], args["to_version"]
+	if fromVersion == "" || toVersion == "" {
+		return nil, fmt.Errorf("from_version and to_version are required")
+	}
+
+	plan, err := s.docManager.PlanUpgrade(ctx, fromVersion, toVersion, docs.UpgradeFilter{Component: args["component"]})
+	if err != nil {
+		return nil, err
+	}
+	items := plan.Items
+
+	var instructions strings.Builder
+	instructions.WriteString(fmt.Sprintf("Plan an upgrade of a Laravel application from %s to %s", fromVersion, toVersion))
+	if args["component"] != "" {
+		instructions.WriteString(fmt.Sprintf(", limited to changes affecting %s", args["component"]))
+	}
+	instructions.WriteString(". Produce an ordered checklist grouped by target version, putting high impact changes first, ")
+	instructions.WriteString("and for each step name the code to search for and the change to make. ")
+	instructions.WriteString("Use the upgrade guide entries attached below.")
+	if len(plan.Missing) > 0 {
+		instructions.WriteString(fmt.Sprintf(" The upgrade guides for %s are not available locally; point out that those steps are missing.", strings.Join(plan.Missing, ", ")))
+	}
+	if len(items) > maxPromptUpgradeItems {
+		instructions.WriteString(fmt.Sprintf(" Only the %d highest impact of %d entries are attached; mention that the rest are lower impact.", maxPromptUpgradeItems, len(items)))
+		items = highestImpact(items, maxPromptUpgradeItems)
+	}
+
+	messages := []*mcp.PromptMessage{userText(instructions.String())}
+	for _, item := range items {
+		text := fmt.Sprintf("Likelihood of impact: %s\n\n%s", item.Impact, item.Content)
+		if item.Component != "" && item.Component != item.Title {
+			text = fmt.Sprintf("Component: %s\n%s", item.Component, text)
+		}
+		messages = append(messages, embeddedDoc(docURI(item.TargetVersion, "upgrade.md", item.Anchor), text))
+	}
+
+	return &mcp.GetPromptResult{
+		Description: fmt.Sprintf("Upgrade plan from %s to %s using %d guide entries", fromVersion, toVersion, len(items)),
+		Messages:    messages,
+	}, nil
+}
+
+// choosePackagePrompt embeds the recommended catalog entries and related docs for a use case
+func (s *Server) choosePackagePrompt(ctx context.Context, catalog *packages.Catalog, request *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	useCase := request.Params.Arguments["use_case"]
+	if useCase == "" {
+		return nil, fmt.Errorf("use_case is required")
+	}
+	version := request.Params.Arguments["version"]
+
+	recommendations, err := catalog.Recommend(ctx, useCase, maxPromptPackages)
+	if err != nil {
+		return nil, err
+	}
+
+	var instructions strings.Builder
+	instructions.WriteString(fmt.Sprintf("Help me choose a Laravel package for: %s", useCase))
+	if version != "" {
+		instructions.WriteString(fmt.Sprintf(" (the project uses Laravel %s)", version))
+	}
+	instructions.WriteString(". Compare the candidate packages attached below on fit, maintenance and minimum Laravel version, ")
+	instructions.WriteString("recommend one, and show how to install and start using it. ")
+	instructions.WriteString("Mention when a built-in Laravel feature from the attached documentation is enough on its own.")
+	if len(recommendations) == 0 {
+		instructions.WriteString(" No catalog packages matched this use case; suggest where to look instead.")
+	}
+
+	messages := []*mcp.PromptMessage{userText(instructions.String())}
+	for _, pkg := range recommendations {
+		details, err := packages.FormatPackageDetails(&pkg)
+		if err != nil {
+			continue
+		}
+		uri := fmt.Sprintf("%s://%s", packagesURIScheme, pkg.ComposerName)
+		messages = append(messages, embeddedDoc(uri, details))
+	}
+
+	// Built-in features often make a package unnecessary
+	if hits, err := s.docManager.Search(ctx, useCase, version, 1); err == nil {
+		for _, hit := range hits {
+			if section, err := s.docManager.GetSection(ctx, hit.Version, hit.File, hit.Anchor, true); err == nil {
+				messages = append(messages, embeddedDoc(docURI(hit.Version, hit.File, hit.Anchor), section.Content))
+			}
+		}
+	}
+
+	return &mcp.GetPromptResult{
+		Description: fmt.Sprintf("Choose a package for %s from %d candidates", useCase, len(recommendations)),
+		Messages:    messages,
+	}, nil
+}
+
+// highestImpact keeps the limit highest impact items, preserving their order
+func highestImpact(items []docs.UpgradeItem, limit int) []docs.UpgradeItem {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		rankA, _ := docs.ImpactRank(items[order[a]].Impact)
+		rankB, _ := docs.ImpactRank(items[order[b]].Impact)
+		return rankA > rankB
+	})
+
+	keep := make(map[int]bool, limit)
+	for _, i := range order[:limit] {
+		keep[i] = true
+	}
+
+	kept := make([]docs.UpgradeItem, 0, limit)
+	for i, item := range items {
+		if keep[i] {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// userText creates a user message holding plain text
+func userText(text string) *mcp.PromptMessage {
+	return &mcp.PromptMessage{
+		Role:    "user",
+		Content: &mcp.TextContent{Text: text},
+	}
+}
+
+// embeddedDoc creates a user message embedding Markdown content as a resource
+func embeddedDoc(uri, text string) *mcp.PromptMessage {
+	return &mcp.PromptMessage{
+		Role: "user",
+		Content: &mcp.EmbeddedResource{
+			Resource: &mcp.ResourceContents{URI: uri, MIMEType: docsMIMEType, Text: text},
+		},
+	}
+}
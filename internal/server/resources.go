@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/docs"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// docsURIScheme is the scheme of documentation resource URIs
+	docsURIScheme = "laravel-docs"
+
+	// docsMIMEType is the MIME type of documentation resources
+	docsMIMEType = "text/markdown"
+
+	// listPageSize is the number of items returned per page by list methods
+	listPageSize = 100
+)
+
+// docURI builds the resource URI for a documentation file, optionally pointing at a section
+func docURI(version, filename, anchor string) string {
+	uri := fmt.Sprintf("%s://%s/%s", docsURIScheme, version, filename)
+	if anchor != "" {
+		uri += "#" + anchor
+	}
+	return uri
+}
+
+// parseDocURI splits a documentation resource URI into version, file and section anchor
+func parseDocURI(uri string) (version, filename, anchor string, err error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid resource URI: %w", err)
+	}
+	if parsed.Scheme != docsURIScheme {
+		return "", "", "", fmt.Errorf("unsupported resource scheme: %s", parsed.Scheme)
+	}
+
+	filename = strings.TrimPrefix(parsed.Path, "/")
+	if parsed.Host == "" || filename == "" || strings.Contains(filename, "/") {
+		return "", "", "", fmt.Errorf("resource URI must look like %s://{version}/{file}", docsURIScheme)
+	}
+
+	return parsed.Host, filename, parsed.Fragment, nil
+}
+
+// RegisterDocResources exposes every documentation file as an MCP resource
+// and registers URI templates for files and sections of any version
+func (s *Server) RegisterDocResources(ctx context.Context) int {
+	s.mcp.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "laravel-doc",
+		Title:       "Laravel documentation file",
+		Description: "A Laravel documentation file for a version, e.g. laravel-docs://12.x/queues.md",
+		MIMEType:    docsMIMEType,
+		URITemplate: docsURIScheme + "://{version}/{file}",
+	}, s.readDocResource)
+
+	s.mcp.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "laravel-doc-section",
+		Title:       "Laravel documentation section",
+		Description: "A single section of a Laravel documentation file addressed by heading anchor, e.g. laravel-docs://12.x/queues.md#job-batching",
+		MIMEType:    docsMIMEType,
+		URITemplate: docsURIScheme + "://{version}/{file}{#section}",
+	}, s.readDocResource)
+
+	total := 0
+	for _, version := range s.docManager.AvailableVersions() {
+		total += s.RefreshDocResources(ctx, version)
+	}
+	return total
+}
+
+// RefreshDocResources re-registers the resources of one version after its files changed
+// and returns the number of resources registered. A cancelled ctx leaves the current
+// resources in place.
+func (s *Server) RefreshDocResources(ctx context.Context, version string) int {
+	s.resourcesMu.Lock()
+	defer s.resourcesMu.Unlock()
+
+	files, err := s.docManager.ListDocs(ctx, version)
+	if ctx.Err() != nil {
+		return len(s.docResources[version])
+	}
+
+	if stale := s.docResources[version]; len(stale) > 0 {
+		s.mcp.RemoveResources(stale...)
+	}
+	delete(s.docResources, version)
+
+	if err != nil {
+		return 0
+	}
+
+	uris := make([]string, 0, len(files))
+	for _, file := range files {
+		uri := docURI(version, file, "")
+		s.mcp.AddResource(&mcp.Resource{
+			Name:        version + "/" + file,
+			Title:       fmt.Sprintf("Laravel %s: %s", version, strings.TrimSuffix(file, ".md")),
+			Description: fmt.Sprintf("Laravel %s documentation for %s", version, strings.TrimSuffix(file, ".md")),
+			MIMEType:    docsMIMEType,
+			URI:         uri,
+		}, s.readDocResource)
+		uris = append(uris, uri)
+	}
+
+	s.docResources[version] = uris
+	return len(uris)
+}
+
+// readDocResource serves resources/read for documentation files and sections
+func (s *Server) readDocResource(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := request.Params.URI
+
+	version, filename, anchor, err := parseDocURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	var text string
+	if anchor == "" {
+		text, err = s.docManager.ReadDoc(ctx, version, filename)
+	} else {
+		var section *docs.SectionContent
+		section, err = s.docManager.GetSection(ctx, version, filename, anchor, true)
+		if err == nil {
+			text = section.Content
+		}
+	}
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: uri, MIMEType: docsMIMEType, Text: text},
+		},
+	}, nil
+}
@@ -1,8 +1,12 @@
 package server
 
 import (
+	"context"
+	"sync"
+
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/docs"
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/external"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/logging"
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/updater"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -13,6 +17,14 @@ type Server struct {
 	docManager      *docs.Manager
 	externalManager *external.ExternalManager
 	updater         *updater.GitHubUpdater
+
+	// docResources tracks the resource URIs registered per version
+	docResources map[string][]string
+	resourcesMu  sync.Mutex
+
+	// bootstrapping is the version downloaded by BootstrapDocs while it runs
+	bootstrapping string
+	bootstrapMu   sync.Mutex
 }
 
 // NewServer creates a new server instance
@@ -25,13 +37,15 @@ func NewServer(docManager *docs.Manager) *Server {
 	opts := &mcp.ServerOptions{
 		Instructions: "Laravel documentation and package recommendations for AI assistants",
 		HasTools:     true,
+		PageSize:     listPageSize,
 	}
 
 	mcpServer := mcp.NewServer(impl, opts)
 
 	return &Server{
-		mcp:        mcpServer,
-		docManager: docManager,
+		mcp:          mcpServer,
+		docManager:   docManager,
+		docResources: make(map[string][]string),
 	}
 }
 
@@ -49,3 +63,35 @@ func (s *Server) SetUpdater(u *updater.GitHubUpdater) {
 func (s *Server) GetMCPServer() *mcp.Server {
 	return s.mcp
 }
+
+// progressNotifier returns a progress callback that forwards to the client as MCP
+// progress notifications, or nil when the request did not ask for progress
+func progressNotifier(ctx context.Context, req *mcp.CallToolRequest) func(done, total int, message string) {
+	token := req.Params.GetProgressToken()
+	if token == nil || req.Session == nil {
+		return nil
+	}
+
+	var mu sync.Mutex
+	last := 0
+	return func(done, total int, message string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Progress must not go backwards; workers may report out of order
+		if done < last {
+			return
+		}
+		last = done
+
+		params := &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Message:       message,
+			Progress:      float64(done),
+			Total:         float64(total),
+		}
+		if err := req.Session.NotifyProgress(ctx, params); err != nil {
+			logging.Debug("Failed to send progress notification: %v", err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/logging"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Supported transports
+const (
+	TransportStdio = "stdio"
+	TransportSSE   = "sse"
+	TransportHTTP  = "http"
+)
+
+const (
+	// shutdownTimeout bounds how long in-flight requests may take to finish on shutdown
+	shutdownTimeout = 10 * time.Second
+
+	// sessionIdleTimeout closes streamable HTTP sessions that stopped sending requests
+	sessionIdleTimeout = 30 * time.Minute
+)
+
+// Serve runs the server over the given transport until ctx is cancelled.
+// HTTP transports listen on addr and give every client its own MCP session,
+// so subscriptions, progress tokens and log levels never leak between editors.
+func (s *Server) Serve(ctx context.Context, transport, addr string) error {
+	switch transport {
+	case TransportStdio, "":
+		err := s.mcp.Run(ctx, &mcp.StdioTransport{})
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	case TransportSSE:
+		handler := mcp.NewSSEHandler(func(*http.Request) *mcp.Server { return s.mcp }, nil)
+		return serveHTTP(ctx, addr, handler)
+	case TransportHTTP:
+		handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return s.mcp }, &mcp.StreamableHTTPOptions{
+			SessionTimeout: sessionIdleTimeout,
+		})
+		return serveHTTP(ctx, addr, handler)
+	default:
+		return fmt.Errorf("unknown transport: %s (use stdio, sse or http)", transport)
+	}
+}
+
+// serveHTTP serves handler on addr and shuts down gracefully when ctx is cancelled
+func serveHTTP(ctx context.Context, addr string, handler http.Handler) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+	logging.Info("Listening on http://%s", listener.Addr())
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	logging.Info("Shutting down, waiting up to %s for open requests...", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		// Long-lived event streams never go idle on their own
+		logging.Warn("Graceful shutdown incomplete, closing remaining connections: %v", err)
+		return httpServer.Close()
+	}
+	return nil
+}
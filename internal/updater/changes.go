@@ -0,0 +1,207 @@
+package updater
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxChangelogFiles limits how many files are listed per change kind in a summary
+const maxChangelogFiles = 20
+
+// SyncReport describes what a documentation sync changed
+type SyncReport struct {
+	Version        string
+	PreviousCommit string // empty on the first sync
+	Commit         string
+	UpToDate       bool // the stored commit already matched the branch head
+	Full           bool // every file was downloaded because force was set
+
+	Added     []string
+	Modified  []string
+	Removed   []string
+	Unchanged int
+
+	Downloaded int // files downloaded by this run
+	Resumed    int // files downloaded by an interrupted earlier run
+}
+
+// Summary returns a human readable changelog of the sync
+func (r *SyncReport) Summary() string {
+	if r.UpToDate {
+		return fmt.Sprintf("Laravel %s documentation is already up to date (commit: %s). Use force=true to download it again.",
+			r.Version, shortSHA(r.Commit))
+	}
+
+	var output strings.Builder
+	if r.PreviousCommit == "" || r.PreviousCommit == r.Commit {
+		output.WriteString(fmt.Sprintf("Successfully updated %s documentation (commit: %s)\n", r.Version, shortSHA(r.Commit)))
+	} else {
+		output.WriteString(fmt.Sprintf("Successfully updated %s documentation from %s to %s\n",
+			r.Version, shortSHA(r.PreviousCommit), shortSHA(r.Commit)))
+	}
+	output.WriteString(fmt.Sprintf("- %d added, %d modified, %d removed, %d unchanged files\n",
+		len(r.Added), len(r.Modified), len(r.Removed), r.Unchanged))
+
+	downloaded := fmt.Sprintf("- %d files downloaded", r.Downloaded)
+	if r.Full {
+		downloaded += " (forced full download)"
+	}
+	if r.Resumed > 0 {
+		downloaded += fmt.Sprintf(", %d resumed from an interrupted sync", r.Resumed)
+	}
+	output.WriteString(downloaded + "\n")
+
+	writeChangedFiles(&output, "Added", r.Added)
+	writeChangedFiles(&output, "Modified", r.Modified)
+	writeChangedFiles(&output, "Removed", r.Removed)
+
+	return strings.TrimSuffix(output.String(), "\n")
+}
+
+// Changed reports whether the sync added, modified or removed any file
+func (r *SyncReport) Changed() bool {
+	return len(r.Added)+len(r.Modified)+len(r.Removed) > 0
+}
+
+// writeChangedFiles writes one changelog section
+func writeChangedFiles(output *strings.Builder, title string, files []string) {
+	if len(files) == 0 {
+		return
+	}
+	output.WriteString(fmt.Sprintf("\n## %s\n", title))
+	for i, file := range files {
+		if i >= maxChangelogFiles {
+			output.WriteString(fmt.Sprintf("- ...and %d more\n", len(files)-i))
+			break
+		}
+		output.WriteString(fmt.Sprintf("- %s\n", file))
+	}
+}
+
+// syncPlan lists what a sync has to do with every file
+type syncPlan struct {
+	download  []string // files to fetch from GitHub
+	unchanged []string // files to carry over from the live copy
+	refreshed []string // unchanged files downloaded again because force was set
+	added     []string
+	modified  []string
+	removed   []string
+}
+
+// planSync compares the files at the branch head with the live copy. Blob SHAs
+// recorded by the last sync are trusted; files without one are hashed locally.
+func planSync(versionPath string, known, head map[string]string, force bool) syncPlan {
+	var plan syncPlan
+
+	for _, file := range sortedKeys(head) {
+		localPath := filepath.Join(versionPath, file)
+		localSHA, recorded := known[file]
+		if _, err := os.Stat(localPath); err != nil {
+			// Missing locally, whatever the metadata says
+			localSHA, recorded = "", false
+		} else if !recorded {
+			localSHA, _ = gitBlobSHA(localPath)
+		}
+
+		switch {
+		case localSHA == "":
+			plan.added = append(plan.added, file)
+			plan.download = append(plan.download, file)
+		case localSHA != head[file]:
+			plan.modified = append(plan.modified, file)
+			plan.download = append(plan.download, file)
+		case force:
+			plan.refreshed = append(plan.refreshed, file)
+			plan.download = append(plan.download, file)
+		default:
+			plan.unchanged = append(plan.unchanged, file)
+		}
+	}
+
+	// Anything in the live copy but not at the branch head was removed upstream
+	local := make(map[string]bool)
+	for file := range known {
+		local[file] = true
+	}
+	if entries, err := os.ReadDir(versionPath); err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() && filepath.Ext(entry.Name()) == ".md" {
+				local[entry.Name()] = true
+			}
+		}
+	}
+	for _, file := range sortedKeys(local) {
+		if _, ok := head[file]; !ok {
+			plan.removed = append(plan.removed, file)
+		}
+	}
+
+	return plan
+}
+
+// gitBlobSHA computes the SHA git assigns to a file's content
+func gitBlobSHA(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return blobSHA(data), nil
+}
+
+// blobSHA computes the SHA git assigns to content
+func blobSHA(data []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(data))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// copyFile copies src to dst, creating parent directories as needed
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// shortSHA abbreviates a commit SHA for display
+func shortSHA(sha string) string {
+	if sha == "" {
+		return "unknown"
+	}
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
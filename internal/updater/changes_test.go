@@ -0,0 +1,98 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlanSync(t *testing.T) {
+	versionPath := t.TempDir()
+	local := map[string]string{"routing.md": "# Routing", "mail.md": "# Mail", "queues.md": "# Queues"}
+	for name, content := range local {
+		if err := os.WriteFile(filepath.Join(versionPath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	known := map[string]string{
+		"routing.md": blobSHA([]byte("# Routing")),
+		"mail.md":    blobSHA([]byte("# Mail")),
+		// queues.md has no recorded SHA and is hashed locally
+	}
+
+	head := map[string]string{
+		"routing.md": blobSHA([]byte("# Routing")),
+		"mail.md":    blobSHA([]byte("# Mail\n\nUpdated")),
+		"horizon.md": blobSHA([]byte("# Horizon")),
+	}
+
+	plan := planSync(versionPath, known, head, false)
+
+	want := syncPlan{
+		download:  []string{"horizon.md", "mail.md"},
+		unchanged: []string{"routing.md"},
+		added:     []string{"horizon.md"},
+		modified:  []string{"mail.md"},
+		removed:   []string{"queues.md"},
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("Unexpected plan:\n got %+v\nwant %+v", plan, want)
+	}
+
+	// Forcing re-downloads unchanged files too
+	plan = planSync(versionPath, known, head, true)
+	if len(plan.unchanged) != 0 || !reflect.DeepEqual(plan.refreshed, []string{"routing.md"}) || len(plan.download) != 3 {
+		t.Errorf("Expected every file to be downloaded when forced, got %+v", plan)
+	}
+
+	// A file recorded in metadata but missing on disk is downloaded again
+	os.Remove(filepath.Join(versionPath, "routing.md"))
+	plan = planSync(versionPath, known, head, false)
+	if !reflect.DeepEqual(plan.added, []string{"horizon.md", "routing.md"}) {
+		t.Errorf("Expected the missing routing.md to be added, got %+v", plan)
+	}
+}
+
+func TestSyncReport_Summary(t *testing.T) {
+	upToDate := &SyncReport{Version: "12.x", Commit: strings.Repeat("a", 40), UpToDate: true}
+	if summary := upToDate.Summary(); !strings.Contains(summary, "already up to date (commit: aaaaaaa)") {
+		t.Errorf("Unexpected up to date summary: %q", summary)
+	}
+
+	var added []string
+	for i := 0; i < maxChangelogFiles+3; i++ {
+		added = append(added, fmt.Sprintf("doc%02d.md", i))
+	}
+	report := &SyncReport{
+		Version:        "12.x",
+		PreviousCommit: strings.Repeat("a", 40),
+		Commit:         strings.Repeat("b", 40),
+		Full:           true,
+		Added:          added,
+		Modified:       []string{"mail.md"},
+		Removed:        []string{"queues.md"},
+		Unchanged:      4,
+		Downloaded:     len(added) + 5,
+		Resumed:        2,
+	}
+
+	summary := report.Summary()
+	for _, want := range []string{
+		"from aaaaaaa to bbbbbbb",
+		"23 added, 1 modified, 1 removed, 4 unchanged files",
+		"28 files downloaded (forced full download), 2 resumed",
+		"## Modified\n- mail.md",
+		"## Removed\n- queues.md",
+		"- ...and 3 more",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected %q in summary:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "doc20.md") {
+		t.Errorf("Expected the added files to be truncated:\n%s", summary)
+	}
+}
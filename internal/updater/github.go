@@ -1,231 +1,247 @@
 package updater
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/models"
 )
 
-const (
-	githubAPIBase = "https://api.github.com"
-	docsRepo      = "laravel/docs"
-	timeout       = 30 * time.Second
-)
+const timeout = 30 * time.Second
 
-// GitHubUpdater handles documentation updates from GitHub
+// GitHubUpdater handles documentation updates from a DocSource, GitHub by default
 type GitHubUpdater struct {
-	httpClient *http.Client
-	basePath   string
+	source   DocSource
+	basePath string
+	syncMu   sync.Mutex // serializes full syncs, which share staging directories
+	liveMu   sync.Mutex // serializes changes to live version directories and their metadata
+	versions versionCache
+
+	trackMu  sync.Mutex
+	tracking map[string]*syncTracker // in-flight syncs by version
 }
 
-// NewGitHubUpdater creates a new GitHub updater
+// NewGitHubUpdater creates a new updater syncing from github.com
 func NewGitHubUpdater(basePath string) *GitHubUpdater {
 	return &GitHubUpdater{
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
+		source:   NewGitHubSource(githubAPIBase, githubRawBase, docsRepo),
 		basePath: basePath,
 	}
 }
 
-// UpdateDocs updates documentation for a specific version
-func (u *GitHubUpdater) UpdateDocs(version string) (string, error) {
-	// Verify version is supported
-	supported := false
-	for _, v := range models.SupportedVersions {
-		if v == version {
-			supported = true
-			break
-		}
-	}
-	if !supported {
-		return "", fmt.Errorf("unsupported version: %s", version)
-	}
+// SetSource sets the source documentation is synced from
+func (u *GitHubUpdater) SetSource(source DocSource) {
+	u.source = source
 
-	// Fetch commit SHA
-	commitSHA, err := u.getLatestCommitSHA(version)
-	if err != nil {
-		return "", fmt.Errorf("failed to get latest commit: %w", err)
-	}
+	u.versions.mu.Lock()
+	u.versions.versions = nil
+	u.versions.mu.Unlock()
+}
 
-	// Fetch tree of markdown files
-	files, err := u.getMarkdownFiles(version, commitSHA)
+// UpdateDocs updates documentation for a specific version and returns a changelog
+func (u *GitHubUpdater) UpdateDocs(ctx context.Context, version string, force bool) (string, error) {
+	report, err := u.SyncDocs(ctx, version, force, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to get file list: %w", err)
+		return "", err
 	}
+	return report.Summary(), nil
+}
 
-	// Create version directory
-	versionPath := filepath.Join(u.basePath, version)
-	if err := os.MkdirAll(versionPath, 0755); err != nil {
-		return "", fmt.Errorf("failed to create version directory: %w", err)
+// SyncDocs brings a version up to date with its head revision at the source. Unless force is
+// set, only files whose blob SHA changed since the last sync are downloaded. progress,
+// which may be nil, is called after every file. Cancelling ctx stops the sync; files
+// fetched so far are kept for the next attempt to resume from.
+func (u *GitHubUpdater) SyncDocs(ctx context.Context, version string, force bool, progress ProgressFunc) (*SyncReport, error) {
+	if !u.isKnownVersion(ctx, version) {
+		return nil, fmt.Errorf("unsupported version: %s", version)
 	}
 
-	// Download each file
-	downloadedCount := 0
-	for _, file := range files {
-		if err := u.downloadFile(version, file, versionPath); err != nil {
-			return "", fmt.Errorf("failed to download %s: %w", file, err)
-		}
-		downloadedCount++
+	u.syncMu.Lock()
+	defer u.syncMu.Unlock()
+	defer u.trackSync(version)()
+
+	progress.report(0, 0, fmt.Sprintf("Checking Laravel %s for changes", version))
+	commitSHA, err := u.source.HeadRevision(ctx, version)
+	if err != nil {
+		return nil, err
 	}
 
-	// Save metadata
-	metadata := models.DocMetadata{
-		Version:   version,
-		CommitSHA: commitSHA,
-		SyncTime:  time.Now(),
-		FileCount: downloadedCount,
+	if report := u.upToDate(version, commitSHA, force); report != nil {
+		return report, nil
 	}
 
-	if err := u.saveMetadata(versionPath, metadata); err != nil {
-		return "", fmt.Errorf("failed to save metadata: %w", err)
+	files, err := u.source.ListFiles(ctx, version, commitSHA)
+	if err != nil {
+		return nil, err
 	}
 
-	return fmt.Sprintf("Successfully updated %s documentation: %d files downloaded (commit: %s)",
-		version, downloadedCount, commitSHA[:7]), nil
+	return u.applySync(ctx, version, commitSHA, files, force, progress, func(ctx context.Context, file, destPath string) error {
+		return u.fetchWithRetry(ctx, version, commitSHA, file, destPath)
+	})
 }
 
-// getLatestCommitSHA fetches the latest commit SHA for a branch
-func (u *GitHubUpdater) getLatestCommitSHA(version string) (string, error) {
-	url := fmt.Sprintf("%s/repos/%s/commits/%s", githubAPIBase, docsRepo, version)
-
-	resp, err := u.doRequest(url)
-	if err != nil {
-		return "", err
+// upToDate returns an up-to-date report when the stored commit already matches
+// commitSHA, or nil when the version needs syncing
+func (u *GitHubUpdater) upToDate(version, commitSHA string, force bool) *SyncReport {
+	previous := u.loadMetadata(filepath.Join(u.basePath, version))
+	if force || commitSHA == "" || previous == nil || previous.CommitSHA != commitSHA {
+		return nil
 	}
-	defer resp.Body.Close()
 
-	var commit struct {
-		SHA string `json:"sha"`
+	return &SyncReport{
+		Version:        version,
+		PreviousCommit: previous.CommitSHA,
+		Commit:         commitSHA,
+		UpToDate:       true,
+		Unchanged:      previous.FileCount,
 	}
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&commit); err != nil {
-		return "", fmt.Errorf("failed to decode commit response: %w", err)
-	}
+// applySync writes the files of a commit into a version directory. Changed files
+// are fetched into a staging directory, unchanged ones are carried over from the
+// live copy, and the result replaces the live copy in one swap.
+func (u *GitHubUpdater) applySync(ctx context.Context, version, commitSHA string, files map[string]string, force bool, progress ProgressFunc, fetch fetchFunc) (*SyncReport, error) {
+	versionPath := filepath.Join(u.basePath, version)
 
-	return commit.SHA, nil
-}
+	report := &SyncReport{Version: version, Commit: commitSHA, Full: force}
+	var known map[string]string
+	if previous := u.loadMetadata(versionPath); previous != nil {
+		report.PreviousCommit = previous.CommitSHA
+		known = previous.Files
+	}
 
-// getMarkdownFiles fetches list of markdown files in the docs repo
-func (u *GitHubUpdater) getMarkdownFiles(version, commitSHA string) ([]string, error) {
-	url := fmt.Sprintf("%s/repos/%s/git/trees/%s", githubAPIBase, docsRepo, commitSHA)
+	plan := planSync(versionPath, known, files, force)
+	report.Added, report.Modified, report.Removed = plan.added, plan.modified, plan.removed
+	report.Unchanged = len(plan.unchanged) + len(plan.refreshed)
 
-	resp, err := u.doRequest(url)
+	// Fetch into a staging directory so a failed sync never leaves a half-written version
+	result, err := u.syncFiles(ctx, version, commitSHA, plan.download, progress, fetch)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	if len(result.Failed) > 0 {
+		return nil, fmt.Errorf("failed to fetch %d of %d files (run the update again to resume):\n%s",
+			len(result.Failed), len(plan.download), strings.Join(result.Failed, "\n"))
+	}
+	report.Downloaded, report.Resumed = result.Downloaded, result.Resumed
 
-	var tree struct {
-		Tree []struct {
-			Path string `json:"path"`
-			Type string `json:"type"`
-		} `json:"tree"`
+	// Unchanged files are carried over from the live copy
+	stagingPath := u.stagingPath(version)
+	for _, file := range plan.unchanged {
+		if err := copyFile(filepath.Join(versionPath, file), filepath.Join(stagingPath, file)); err != nil {
+			return nil, fmt.Errorf("failed to carry over %s: %w", file, err)
+		}
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&tree); err != nil {
-		return nil, fmt.Errorf("failed to decode tree response: %w", err)
+	// Save metadata
+	metadata := models.DocMetadata{
+		Version:   version,
+		CommitSHA: commitSHA,
+		SyncTime:  time.Now(),
+		FileCount: len(files),
+		Files:     files,
 	}
 
-	// Filter for .md files
-	var files []string
-	for _, item := range tree.Tree {
-		if item.Type == "blob" && filepath.Ext(item.Path) == ".md" {
-			files = append(files, item.Path)
-		}
+	if err := u.saveMetadata(stagingPath, metadata); err != nil {
+		return nil, fmt.Errorf("failed to save metadata: %w", err)
+	}
+
+	u.liveMu.Lock()
+	err = u.swapIn(version)
+	u.liveMu.Unlock()
+	if err != nil {
+		return nil, err
 	}
 
-	return files, nil
+	return report, nil
 }
 
-// downloadFile downloads a single markdown file
-func (u *GitHubUpdater) downloadFile(version, filename, destPath string) error {
-	// Raw content URL
-	url := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", docsRepo, version, filename)
+// DownloadSingleFile downloads a single file from the source and saves it locally,
+// recording its blob SHA so the next sync knows whether it changed
+func (u *GitHubUpdater) DownloadSingleFile(ctx context.Context, version, filename string) (string, error) {
+	if !u.isKnownVersion(ctx, version) {
+		return "", fmt.Errorf("unsupported version: %s", version)
+	}
 
-	resp, err := u.doRequest(url)
-	if err != nil {
-		return err
+	if err := os.MkdirAll(u.basePath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create docs directory: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// Read content
-	content, err := io.ReadAll(resp.Body)
+	// Download next to the version directory, so a running sync is not waited for
+	tmpDir, err := os.MkdirTemp(u.basePath, ".download-")
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return "", fmt.Errorf("failed to create download directory: %w", err)
 	}
+	defer os.RemoveAll(tmpDir)
 
-	// Write to file
-	filePath := filepath.Join(destPath, filename)
-	if err := os.WriteFile(filePath, content, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	if err := u.fetchWithRetry(ctx, version, version, filename, tmpDir); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", filename, err)
 	}
 
-	return nil
-}
-
-// DownloadSingleFile downloads a single file from GitHub and saves it locally
-func (u *GitHubUpdater) DownloadSingleFile(version, filename string) (string, error) {
-	// Verify version is supported
-	supported := false
-	for _, v := range models.SupportedVersions {
-		if v == version {
-			supported = true
-			break
-		}
-	}
-	if !supported {
-		return "", fmt.Errorf("unsupported version: %s", version)
+	// Read the content to return it
+	content, err := os.ReadFile(filepath.Join(tmpDir, filename))
+	if err != nil {
+		return "", fmt.Errorf("failed to read downloaded file: %w", err)
 	}
 
+	// Move the file in while no sync swaps the version directory
+	u.liveMu.Lock()
+	defer u.liveMu.Unlock()
+
 	// Create version directory if it doesn't exist
 	versionPath := filepath.Join(u.basePath, version)
 	if err := os.MkdirAll(versionPath, 0755); err != nil {
 		return "", fmt.Errorf("failed to create version directory: %w", err)
 	}
+	filePath := filepath.Join(versionPath, filename)
+	_, statErr := os.Stat(filePath)
+	isNew := statErr != nil
 
-	// Download the file
-	if err := u.downloadFile(version, filename, versionPath); err != nil {
-		return "", fmt.Errorf("failed to download %s: %w", filename, err)
+	if err := os.Rename(filepath.Join(tmpDir, filename), filePath); err != nil {
+		return "", fmt.Errorf("failed to save %s: %w", filename, err)
 	}
 
-	// Read the content to return it
-	filePath := filepath.Join(versionPath, filename)
-	content, err := os.ReadFile(filePath)
+	sha, err := gitBlobSHA(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read downloaded file: %w", err)
+		return "", fmt.Errorf("failed to hash %s: %w", filename, err)
 	}
 
-	return string(content), nil
-}
-
-// doRequest performs HTTP request with GitHub API headers
-func (u *GitHubUpdater) doRequest(url string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	metadata := u.loadMetadata(versionPath)
+	if metadata == nil {
+		metadata = &models.DocMetadata{Version: version, SyncTime: time.Now()}
+	}
+	if metadata.Files == nil {
+		metadata.Files = make(map[string]string)
+	}
+	metadata.Files[filename] = sha
+	if isNew {
+		metadata.FileCount++
+	}
+	if err := u.saveMetadata(versionPath, *metadata); err != nil {
+		return "", fmt.Errorf("failed to save metadata: %w", err)
 	}
 
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "Laravel-MCP-Companion")
+	return string(content), nil
+}
 
-	resp, err := u.httpClient.Do(req)
+// loadMetadata reads the metadata of a synced version, returning nil when there is none
+func (u *GitHubUpdater) loadMetadata(versionPath string) *models.DocMetadata {
+	data, err := os.ReadFile(filepath.Join(versionPath, ".metadata.json"))
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	var metadata models.DocMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil
 	}
-
-	return resp, nil
+	return &metadata
 }
 
 // saveMetadata saves update metadata to file
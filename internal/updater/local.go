@@ -0,0 +1,290 @@
+package updater
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/models"
+)
+
+// maxArchiveFileSize limits the size of a single file read from an archive
+const maxArchiveFileSize = 10 << 20
+
+// commitPattern matches a full git commit SHA
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// ImportLocal syncs a version from a local laravel/docs git checkout or from a
+// .tar.gz/.zip archive. ref selects the branch, tag or commit of a checkout and
+// defaults to the version name; it must be empty for archives.
+func (u *GitHubUpdater) ImportLocal(ctx context.Context, sourcePath, version, ref string, force bool) (*SyncReport, error) {
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("docs source not found: %w", err)
+	}
+
+	if info.IsDir() {
+		return u.ImportGitCheckout(ctx, sourcePath, version, ref, force)
+	}
+	if ref != "" {
+		return nil, fmt.Errorf("a ref cannot be used with an archive source")
+	}
+	return u.ImportArchive(ctx, sourcePath, version, force)
+}
+
+// ImportGitCheckout syncs a version from a local laravel/docs clone without network access
+func (u *GitHubUpdater) ImportGitCheckout(ctx context.Context, repoPath, version, ref string, force bool) (*SyncReport, error) {
+	// The ref must exist in the checkout, so the version needs no network check
+	if !models.IsVersionName(version) {
+		return nil, fmt.Errorf("invalid version: %s", version)
+	}
+
+	u.syncMu.Lock()
+	defer u.syncMu.Unlock()
+	defer u.trackSync(version)()
+
+	commitSHA, err := resolveGitRef(ctx, repoPath, version, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if report := u.upToDate(version, commitSHA, force); report != nil {
+		return report, nil
+	}
+
+	files, err := gitMarkdownFiles(ctx, repoPath, commitSHA)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.applySync(ctx, version, commitSHA, files, force, nil, func(ctx context.Context, file, destPath string) error {
+		content, err := runGit(ctx, repoPath, "cat-file", "blob", files[file])
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(destPath, file), content, 0644)
+	})
+}
+
+// ImportArchive syncs a version from a .tar.gz or .zip archive of laravel/docs,
+// such as the ones GitHub serves for a branch
+func (u *GitHubUpdater) ImportArchive(ctx context.Context, archivePath, version string, force bool) (*SyncReport, error) {
+	// The archive contents are the docs of the version, so it needs no network check
+	if !models.IsVersionName(version) {
+		return nil, fmt.Errorf("invalid version: %s", version)
+	}
+
+	u.syncMu.Lock()
+	defer u.syncMu.Unlock()
+	defer u.trackSync(version)()
+
+	contents, commitSHA, err := readArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(contents) == 0 {
+		return nil, fmt.Errorf("no markdown files found in %s", archivePath)
+	}
+
+	if report := u.upToDate(version, commitSHA, force); report != nil {
+		return report, nil
+	}
+
+	files := make(map[string]string, len(contents))
+	for file, content := range contents {
+		files[file] = blobSHA(content)
+	}
+
+	return u.applySync(ctx, version, commitSHA, files, force, nil, func(ctx context.Context, file, destPath string) error {
+		return os.WriteFile(filepath.Join(destPath, file), contents[file], 0644)
+	})
+}
+
+// resolveGitRef returns the commit a ref points to, trying the remote-tracking
+// branch when the version has no local branch
+func resolveGitRef(ctx context.Context, repoPath, version, ref string) (string, error) {
+	candidates := []string{ref}
+	if ref == "" {
+		candidates = []string{version, "origin/" + version}
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		out, err := runGit(ctx, repoPath, "rev-parse", "--verify", candidate+"^{commit}")
+		if err == nil {
+			return strings.TrimSpace(string(out)), nil
+		}
+		lastErr = err
+	}
+
+	return "", fmt.Errorf("ref %s not found in %s: %w", candidates[0], repoPath, lastErr)
+}
+
+// gitMarkdownFiles lists the top-level markdown files of a commit with their blob SHAs
+func gitMarkdownFiles(ctx context.Context, repoPath, commitSHA string) (map[string]string, error) {
+	out, err := runGit(ctx, repoPath, "ls-tree", commitSHA)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		// <mode> SP <type> SP <sha> TAB <path>
+		meta, name, ok := strings.Cut(scanner.Text(), "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[1] != "blob" || filepath.Ext(name) != ".md" {
+			continue
+		}
+		files[name] = fields[2]
+	}
+
+	return files, scanner.Err()
+}
+
+// runGit runs a git command in repoPath and returns its output
+func runGit(ctx context.Context, repoPath string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repoPath}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// readArchive returns the markdown files in the docs root of an archive and the
+// commit recorded in the archive comment, if any
+func readArchive(archivePath string) (map[string][]byte, string, error) {
+	name := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return readZip(archivePath)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return readTarGz(archivePath)
+	default:
+		return nil, "", fmt.Errorf("unsupported archive format: %s (use .tar.gz, .tgz or .zip)", filepath.Base(archivePath))
+	}
+}
+
+// readZip reads markdown files from a zip archive
+func readZip(archivePath string) (map[string][]byte, string, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer r.Close()
+
+	entries := make(map[string][]byte)
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || path.Ext(f.Name) != ".md" || f.UncompressedSize64 > maxArchiveFileSize {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxArchiveFileSize))
+		rc.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		entries[f.Name] = content
+	}
+
+	// git archive stores the commit as the zip comment
+	commitSHA := strings.TrimSpace(r.Comment)
+	if !commitPattern.MatchString(commitSHA) {
+		commitSHA = ""
+	}
+
+	return docsRoot(entries), commitSHA, nil
+}
+
+// readTarGz reads markdown files from a gzipped tar archive
+func readTarGz(archivePath string) (map[string][]byte, string, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer gz.Close()
+
+	entries := make(map[string][]byte)
+	commitSHA := ""
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		// git archive stores the commit in a global pax header
+		if comment := header.PAXRecords["comment"]; commitPattern.MatchString(comment) {
+			commitSHA = comment
+		}
+
+		if header.Typeflag != tar.TypeReg || path.Ext(header.Name) != ".md" || header.Size > maxArchiveFileSize {
+			continue
+		}
+
+		content, err := io.ReadAll(io.LimitReader(tr, maxArchiveFileSize))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		entries[header.Name] = content
+	}
+
+	return docsRoot(entries), commitSHA, nil
+}
+
+// docsRoot keeps the markdown files of the shallowest directory holding any,
+// which strips the "docs-12.x/" prefix of GitHub archives, keyed by file name
+func docsRoot(entries map[string][]byte) map[string][]byte {
+	root, depth := "", -1
+	for name := range entries {
+		dir := path.Dir(path.Clean(name))
+		d := 0
+		if dir != "." {
+			d = strings.Count(dir, "/") + 1
+		}
+		if depth == -1 || d < depth || d == depth && dir < root {
+			root, depth = dir, d
+		}
+	}
+
+	files := make(map[string][]byte)
+	for name, content := range entries {
+		clean := path.Clean(name)
+		if path.Dir(clean) == root {
+			files[path.Base(clean)] = content
+		}
+	}
+	return files
+}
@@ -0,0 +1,99 @@
+package updater
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	mcperrors "github.com/izzamoe/laravel-mcp-companion-go/internal/errors"
+)
+
+// maxRateLimitWait is the longest a request waits for a rate limit to reset
+// before failing instead
+const maxRateLimitWait = time.Minute
+
+// rateLimit tracks the GitHub API quota reported by the most recent response
+type rateLimit struct {
+	mu        sync.Mutex
+	limit     int
+	remaining int
+	reset     time.Time
+	known     bool
+}
+
+// update records the quota headers of a response, if it has any
+func (r *rateLimit) update(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remaining = remaining
+	r.limit, _ = strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		r.reset = time.Unix(reset, 0)
+	}
+	r.known = true
+}
+
+// quota returns the request limit of the current window, or 0 when unknown
+func (r *rateLimit) quota() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.limit
+}
+
+// exhausted returns when the quota resets if it is used up and has not reset yet
+func (r *rateLimit) exhausted(now time.Time) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.known || r.remaining > 0 || !r.reset.After(now) {
+		return time.Time{}, false
+	}
+	return r.reset, true
+}
+
+// retryAfter returns when a rate limited response may be retried. It reports
+// false for responses that were not rejected by a rate limit.
+func retryAfter(resp *http.Response, now time.Time) (time.Time, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+
+	// Secondary rate limits say how long to back off
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return now.Add(time.Duration(seconds) * time.Second), true
+	}
+
+	// Primary rate limits say when the quota resets
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Unix(reset, 0), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// rateLimitError reports an exhausted GitHub API quota and when it resets
+func rateLimitError(reset time.Time, limit int, authenticated bool, cause error) *mcperrors.MCPError {
+	wait := time.Until(reset).Round(time.Second)
+	message := fmt.Sprintf("GitHub API rate limit exceeded; it resets at %s (in %s)",
+		reset.UTC().Format(time.RFC3339), max(wait, 0))
+	if !authenticated {
+		message += ". Set GITHUB_TOKEN or --github-token to raise the limit"
+	}
+
+	err := mcperrors.Wrap(mcperrors.ErrGitHubAPI, message, cause).
+		WithDetail("reset", reset.UTC().Format(time.RFC3339))
+	if limit > 0 {
+		err.WithDetail("limit", strconv.Itoa(limit))
+	}
+	return err
+}
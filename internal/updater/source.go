@@ -0,0 +1,393 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/models"
+)
+
+const (
+	githubAPIBase = "https://api.github.com"
+	githubRawBase = "https://raw.githubusercontent.com"
+	docsRepo      = "laravel/docs"
+
+	// branchesPerPage is the page size used when listing repository branches
+	branchesPerPage = 100
+
+	// maxCachedResponses bounds the API responses kept for conditional requests
+	maxCachedResponses = 64
+)
+
+// DocSource is a place documentation versions can be synced from
+type DocSource interface {
+	// ListVersions returns the documentation versions the source offers
+	ListVersions(ctx context.Context) ([]string, error)
+
+	// HeadRevision returns the revision a version currently points at
+	HeadRevision(ctx context.Context, version string) (string, error)
+
+	// ListFiles returns the markdown files of a version at a revision, keyed by
+	// file name, with their git blob SHAs
+	ListFiles(ctx context.Context, version, revision string) (map[string]string, error)
+
+	// FetchFile returns the content of a file of a version at a revision
+	FetchFile(ctx context.Context, version, revision, file string) ([]byte, error)
+}
+
+// ParseDocSource returns the source described by spec: "github" (or empty) for
+// github.com, an http(s) URL for a GitHub Enterprise server or mirror, or a
+// local directory holding one folder per version. token authenticates GitHub
+// requests and may be empty.
+func ParseDocSource(spec, repo, token string) (DocSource, error) {
+	switch {
+	case spec == "" || spec == "github":
+		source := NewGitHubSource(githubAPIBase, githubRawBase, repo)
+		source.SetToken(token)
+		return source, nil
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		if _, err := url.Parse(spec); err != nil {
+			return nil, fmt.Errorf("invalid docs source URL: %w", err)
+		}
+		source := NewGitHubEnterpriseSource(spec, repo)
+		source.SetToken(token)
+		return source, nil
+	default:
+		info, err := os.Stat(spec)
+		if err != nil {
+			return nil, fmt.Errorf("docs source not found: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("docs source %s is not a directory", spec)
+		}
+		return NewFilesystemSource(spec), nil
+	}
+}
+
+// GitHubSource reads documentation from a GitHub repository through the REST API
+// and raw file URLs
+type GitHubSource struct {
+	apiBase    string
+	rawBase    string
+	repo       string
+	token      string
+	httpClient *http.Client
+	rateLimit  rateLimit
+
+	cacheMu sync.Mutex
+	cache   map[string]cachedResponse // API responses by URL, for conditional requests
+}
+
+// cachedResponse is an API response body with the ETag it was served with
+type cachedResponse struct {
+	etag string
+	body []byte
+}
+
+// NewGitHubSource creates a source for repo using the given API and raw file base
+// URLs. Empty values fall back to github.com and laravel/docs.
+func NewGitHubSource(apiBase, rawBase, repo string) *GitHubSource {
+	if apiBase == "" {
+		apiBase = githubAPIBase
+	}
+	if rawBase == "" {
+		rawBase = githubRawBase
+	}
+	if repo == "" {
+		repo = docsRepo
+	}
+
+	return &GitHubSource{
+		apiBase: strings.TrimSuffix(apiBase, "/"),
+		rawBase: strings.TrimSuffix(rawBase, "/"),
+		repo:    repo,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+		cache: make(map[string]cachedResponse),
+	}
+}
+
+// SetToken sets the token requests are authenticated with, which raises the
+// API rate limit and allows reading private mirrors
+func (s *GitHubSource) SetToken(token string) {
+	s.token = token
+}
+
+// NewGitHubEnterpriseSource creates a source for a GitHub Enterprise server or a
+// mirror with the same layout, serving the API under /api/v3 and files under /raw
+func NewGitHubEnterpriseSource(baseURL, repo string) *GitHubSource {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return NewGitHubSource(baseURL+"/api/v3", baseURL+"/raw", repo)
+}
+
+// ListVersions lists the repository branches named like a documentation version
+func (s *GitHubSource) ListVersions(ctx context.Context) ([]string, error) {
+	var versions []string
+
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/repos/%s/branches?per_page=%d&page=%d", s.apiBase, s.repo, branchesPerPage, page)
+
+		var branches []struct {
+			Name string `json:"name"`
+		}
+		if err := s.getJSON(ctx, url, &branches); err != nil {
+			return nil, fmt.Errorf("failed to list branches: %w", err)
+		}
+
+		for _, branch := range branches {
+			if models.IsVersionName(branch.Name) {
+				versions = append(versions, branch.Name)
+			}
+		}
+		if len(branches) < branchesPerPage {
+			break
+		}
+	}
+
+	models.SortVersions(versions)
+	return versions, nil
+}
+
+// HeadRevision fetches the latest commit SHA of a version branch
+func (s *GitHubSource) HeadRevision(ctx context.Context, version string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/commits/%s", s.apiBase, s.repo, version)
+
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	if err := s.getJSON(ctx, url, &commit); err != nil {
+		return "", fmt.Errorf("failed to get latest commit: %w", err)
+	}
+
+	return commit.SHA, nil
+}
+
+// ListFiles fetches the markdown files of a commit with their blob SHAs
+func (s *GitHubSource) ListFiles(ctx context.Context, version, revision string) (map[string]string, error) {
+	url := fmt.Sprintf("%s/repos/%s/git/trees/%s", s.apiBase, s.repo, revision)
+
+	var tree struct {
+		Tree []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+			SHA  string `json:"sha"`
+		} `json:"tree"`
+	}
+	if err := s.getJSON(ctx, url, &tree); err != nil {
+		return nil, fmt.Errorf("failed to get file list: %w", err)
+	}
+
+	// Filter for .md files
+	files := make(map[string]string)
+	for _, item := range tree.Tree {
+		if item.Type == "blob" && filepath.Ext(item.Path) == ".md" {
+			files[item.Path] = item.SHA
+		}
+	}
+
+	return files, nil
+}
+
+// FetchFile downloads a file at a branch or commit
+func (s *GitHubSource) FetchFile(ctx context.Context, version, revision, file string) ([]byte, error) {
+	url := fmt.Sprintf("%s/%s/%s/%s", s.rawBase, s.repo, revision, file)
+
+	resp, err := s.doRequest(ctx, url, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return content, nil
+}
+
+// getJSON fetches url and decodes the JSON response into v. Responses are cached
+// with their ETag so repeated requests for unchanged data do not use quota.
+func (s *GitHubSource) getJSON(ctx context.Context, url string, v any) error {
+	s.cacheMu.Lock()
+	cached, hasCached := s.cache[url]
+	s.cacheMu.Unlock()
+
+	resp, err := s.doRequest(ctx, url, cached.etag)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body := cached.body
+	if resp.StatusCode != http.StatusNotModified || !hasCached {
+		body, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			s.cacheMu.Lock()
+			if len(s.cache) >= maxCachedResponses {
+				clear(s.cache)
+			}
+			s.cache[url] = cachedResponse{etag: etag, body: body}
+			s.cacheMu.Unlock()
+		}
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// doRequest performs HTTP request with GitHub API headers. A non-empty etag makes
+// the request conditional, so 304 Not Modified is accepted too. Requests rejected
+// by a rate limit that resets within maxRateLimitWait are retried once it does.
+func (s *GitHubSource) doRequest(ctx context.Context, url, etag string) (*http.Response, error) {
+	// Don't spend a request while the quota is known to be used up
+	if reset, ok := s.rateLimit.exhausted(time.Now()); ok {
+		if err := s.waitForReset(ctx, reset, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	for waited := false; ; waited = true {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+		req.Header.Set("User-Agent", "Laravel-MCP-Companion")
+		if s.token != "" {
+			req.Header.Set("Authorization", "Bearer "+s.token)
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		s.rateLimit.update(resp.Header)
+
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotModified && etag != "" {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		statusErr := &statusError{StatusCode: resp.StatusCode}
+		reset, limited := retryAfter(resp, time.Now())
+		if !limited {
+			return nil, statusErr
+		}
+		if waited {
+			return nil, rateLimitError(reset, s.rateLimit.quota(), s.token != "", statusErr)
+		}
+		if err := s.waitForReset(ctx, reset, statusErr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitForReset sleeps until a rate limit resets, or fails with the reset time
+// when that is more than maxRateLimitWait away
+func (s *GitHubSource) waitForReset(ctx context.Context, reset time.Time, cause error) error {
+	wait := time.Until(reset)
+	if wait > maxRateLimitWait {
+		return rateLimitError(reset, s.rateLimit.quota(), s.token != "", cause)
+	}
+	// Reset times have one second resolution
+	return sleepContext(ctx, max(wait, 0)+time.Second)
+}
+
+// FilesystemSource reads documentation from a local directory holding one folder
+// of markdown files per version, such as another server's docs path. It only
+// serves the current contents, so the revision is a hash of them.
+type FilesystemSource struct {
+	root string
+}
+
+// NewFilesystemSource creates a source for a local directory
+func NewFilesystemSource(root string) *FilesystemSource {
+	return &FilesystemSource{root: root}
+}
+
+// ListVersions lists the version folders that contain markdown files
+func (s *FilesystemSource) ListVersions(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read docs source: %w", err)
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !models.IsVersionName(entry.Name()) {
+			continue
+		}
+		if files, err := s.ListFiles(ctx, entry.Name(), ""); err == nil && len(files) > 0 {
+			versions = append(versions, entry.Name())
+		}
+	}
+
+	models.SortVersions(versions)
+	return versions, nil
+}
+
+// HeadRevision hashes the file names and blob SHAs of a version folder, so the
+// revision changes whenever any file does
+func (s *FilesystemSource) HeadRevision(ctx context.Context, version string) (string, error) {
+	files, err := s.ListFiles(ctx, version, "")
+	if err != nil {
+		return "", err
+	}
+
+	var tree strings.Builder
+	for _, file := range sortedKeys(files) {
+		fmt.Fprintf(&tree, "%s %s\n", files[file], file)
+	}
+	return blobSHA([]byte(tree.String())), nil
+}
+
+// ListFiles hashes the markdown files of a version folder
+func (s *FilesystemSource) ListFiles(ctx context.Context, version, revision string) (map[string]string, error) {
+	versionPath := filepath.Join(s.root, version)
+	entries, err := os.ReadDir(versionPath)
+	if err != nil {
+		return nil, fmt.Errorf("version %s not found in docs source: %w", version, err)
+	}
+
+	files := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+			continue
+		}
+		sha, err := gitBlobSHA(filepath.Join(versionPath, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		files[entry.Name()] = sha
+	}
+
+	return files, nil
+}
+
+// FetchFile reads a file of a version folder
+func (s *FilesystemSource) FetchFile(ctx context.Context, version, revision, file string) ([]byte, error) {
+	if filepath.Base(file) != file {
+		return nil, fmt.Errorf("invalid file name: %s", file)
+	}
+	return os.ReadFile(filepath.Join(s.root, version, file))
+}
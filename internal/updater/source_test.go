@@ -0,0 +1,87 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	mcperrors "github.com/izzamoe/laravel-mcp-companion-go/internal/errors"
+)
+
+func TestGitHubUpdater_SyncFromEnterpriseSource(t *testing.T) {
+	repo := newFakeGitHub(map[string]string{"routing.md": "# Routing", "mail.md": "# Mail"})
+	u := newTestUpdater(t, repo)
+
+	versions, err := u.RemoteVersions(context.Background())
+	if err != nil {
+		t.Fatalf("RemoteVersions failed: %v", err)
+	}
+	if strings.Join(versions, ",") != "12.x,11.x" {
+		t.Errorf("Expected versions 12.x,11.x, got %v", versions)
+	}
+
+	report, err := u.SyncDocs(context.Background(), "12.x", false, nil)
+	if err != nil {
+		t.Fatalf("SyncDocs failed: %v", err)
+	}
+	if len(report.Added) != 2 || report.Downloaded != 2 {
+		t.Errorf("Expected 2 added and downloaded files, got %d added, %d downloaded", len(report.Added), report.Downloaded)
+	}
+
+	content, err := os.ReadFile(filepath.Join(u.basePath, "12.x", "routing.md"))
+	if err != nil || string(content) != "# Routing" {
+		t.Errorf("Expected synced routing.md, got %q (%v)", content, err)
+	}
+	if metadata := u.loadMetadata(filepath.Join(u.basePath, "12.x")); metadata == nil || metadata.CommitSHA != repo.commit {
+		t.Errorf("Expected metadata for commit %s, got %+v", repo.commit, metadata)
+	}
+}
+
+func TestGitHubSource_TokenAndRateLimit(t *testing.T) {
+	repo := newFakeGitHub(map[string]string{"routing.md": "# Routing"})
+	server := httptest.NewServer(repo)
+	defer server.Close()
+
+	source, err := ParseDocSource(server.URL, "laravel/docs", "secret")
+	if err != nil {
+		t.Fatalf("ParseDocSource failed: %v", err)
+	}
+	u := NewGitHubUpdater(t.TempDir())
+	u.SetSource(source)
+
+	if _, err := u.SyncDocs(context.Background(), "12.x", false, nil); err != nil {
+		t.Fatalf("SyncDocs failed: %v", err)
+	}
+	if repo.auth != "Bearer secret" {
+		t.Errorf("Expected token to be sent, got Authorization %q", repo.auth)
+	}
+
+	// An unchanged head is answered from the ETag cache
+	report, err := u.SyncDocs(context.Background(), "12.x", false, nil)
+	if err != nil {
+		t.Fatalf("SyncDocs failed: %v", err)
+	}
+	if !report.UpToDate || repo.notModified != 1 {
+		t.Errorf("Expected a conditional request and an up-to-date report, got %+v with %d 304s", report, repo.notModified)
+	}
+
+	// A rate limit that resets later fails with the reset time
+	reset := time.Now().Add(time.Hour).Unix()
+	repo.mu.Lock()
+	repo.limitReset = reset
+	repo.mu.Unlock()
+
+	_, err = u.SyncDocs(context.Background(), "12.x", false, nil)
+	var mcpErr *mcperrors.MCPError
+	if !errors.As(err, &mcpErr) || mcpErr.Code != mcperrors.ErrGitHubAPI {
+		t.Fatalf("Expected a GitHub API error, got %v", err)
+	}
+	if mcpErr.Details["reset"] != time.Unix(reset, 0).UTC().Format(time.RFC3339) {
+		t.Errorf("Expected reset detail, got %v", mcpErr.Details)
+	}
+}
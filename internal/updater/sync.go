@@ -0,0 +1,332 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	mcperrors "github.com/izzamoe/laravel-mcp-companion-go/internal/errors"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/models"
+)
+
+const (
+	// syncWorkers is the number of files downloaded concurrently
+	syncWorkers = 8
+
+	// maxAttempts is how often a file download is tried before giving up
+	maxAttempts = 4
+
+	// retryBaseDelay is the wait before the first retry; it doubles on every attempt
+	retryBaseDelay = 500 * time.Millisecond
+
+	// progressFile records which files of a staged sync are complete
+	progressFile = ".progress.json"
+)
+
+// fetchFunc writes one documentation file into destPath
+type fetchFunc func(ctx context.Context, file, destPath string) error
+
+// ProgressFunc receives the progress of a sync: done of total files, where total
+// is zero while it is not known yet, and a short description
+type ProgressFunc func(done, total int, message string)
+
+// report calls the progress function, if there is one
+func (p ProgressFunc) report(done, total int, message string) {
+	if p != nil {
+		p(done, total, message)
+	}
+}
+
+// statusError is returned for unexpected HTTP status codes
+type statusError struct {
+	StatusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// syncProgress is persisted in the staging directory so an interrupted sync can resume
+type syncProgress struct {
+	Version   string          `json:"version"`
+	CommitSHA string          `json:"commit_sha"`
+	Completed map[string]bool `json:"completed"`
+}
+
+// syncResult summarizes a staged download
+type syncResult struct {
+	Downloaded int      // files downloaded by this run
+	Resumed    int      // files already completed by an earlier run
+	Failed     []string // files that failed after all retries
+}
+
+// syncTracker counts the files fetched by an in-flight sync
+type syncTracker struct {
+	done  int
+	total int // zero until the file list is known
+}
+
+// trackSync marks a version as syncing until the returned function is called
+func (u *GitHubUpdater) trackSync(version string) func() {
+	u.trackMu.Lock()
+	defer u.trackMu.Unlock()
+
+	if u.tracking == nil {
+		u.tracking = make(map[string]*syncTracker)
+	}
+	u.tracking[version] = &syncTracker{}
+
+	return func() {
+		u.trackMu.Lock()
+		defer u.trackMu.Unlock()
+		delete(u.tracking, version)
+	}
+}
+
+// updateTracker applies fn to the tracker of a version, if it is syncing
+func (u *GitHubUpdater) updateTracker(version string, fn func(t *syncTracker)) {
+	u.trackMu.Lock()
+	defer u.trackMu.Unlock()
+
+	if t, ok := u.tracking[version]; ok {
+		fn(t)
+	}
+}
+
+// SyncProgress reports how many of the files a sync of version fetches are done,
+// and whether such a sync is running at all
+func (u *GitHubUpdater) SyncProgress(version string) (done, total int, syncing bool) {
+	u.trackMu.Lock()
+	defer u.trackMu.Unlock()
+
+	t, ok := u.tracking[version]
+	if !ok {
+		return 0, 0, false
+	}
+	return t.done, t.total, true
+}
+
+// stagingPath returns the directory a version is synced into before it replaces the live copy
+func (u *GitHubUpdater) stagingPath(version string) string {
+	return filepath.Join(u.basePath, ".staging-"+version)
+}
+
+// syncFiles fetches the files of a commit into the staging directory with a bounded
+// worker pool, skipping files a previous run for the same commit already completed
+func (u *GitHubUpdater) syncFiles(ctx context.Context, version, commitSHA string, files []string, progress ProgressFunc, fetch fetchFunc) (*syncResult, error) {
+	stagingPath := u.stagingPath(version)
+
+	saved := u.loadProgress(stagingPath)
+	if saved == nil || saved.CommitSHA != commitSHA || commitSHA == "" {
+		// Nothing to resume, the branch moved on since the last attempt, or the
+		// source has no commit to tell whether the staged files still match
+		if err := os.RemoveAll(stagingPath); err != nil {
+			return nil, fmt.Errorf("failed to clear staging directory: %w", err)
+		}
+		saved = &syncProgress{Version: version, CommitSHA: commitSHA, Completed: make(map[string]bool)}
+	}
+	if err := os.MkdirAll(stagingPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	result := &syncResult{}
+	var pending []string
+	for _, file := range files {
+		if saved.Completed[file] {
+			result.Resumed++
+			continue
+		}
+		pending = append(pending, file)
+	}
+	u.updateTracker(version, func(t *syncTracker) {
+		t.done, t.total = result.Resumed, len(files)
+	})
+	progress.report(result.Resumed, len(files), fmt.Sprintf("Downloading %d files", len(pending)))
+
+	var mu sync.Mutex
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+
+	for i := 0; i < min(syncWorkers, len(pending)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				err := fetch(ctx, file, stagingPath)
+				if ctx.Err() != nil {
+					// Cancelled mid-download; the file is not recorded as complete, so
+					// drop whatever was written and fetch it again on resume
+					_ = os.Remove(filepath.Join(stagingPath, file))
+					continue
+				}
+
+				mu.Lock()
+				if err != nil {
+					result.Failed = append(result.Failed, fmt.Sprintf("%s: %v", file, err))
+				} else {
+					result.Downloaded++
+					saved.Completed[file] = true
+					u.updateTracker(version, func(t *syncTracker) { t.done++ })
+					// Persisting after every file keeps a crash from losing finished work
+					_ = u.saveProgress(stagingPath, saved)
+				}
+				done := result.Resumed + result.Downloaded
+				mu.Unlock()
+
+				if err == nil {
+					progress.report(done, len(files), file)
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, file := range pending {
+		select {
+		case jobs <- file:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("sync cancelled after %d of %d files: %w", result.Resumed+result.Downloaded, len(files), err)
+	}
+
+	sort.Strings(result.Failed)
+	return result, nil
+}
+
+// fetchWithRetry fetches a file from the source into destPath, retrying transient
+// failures with exponential backoff
+func (u *GitHubUpdater) fetchWithRetry(ctx context.Context, version, revision, filename, destPath string) error {
+	var err error
+	delay := retryBaseDelay
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var content []byte
+		content, err = u.source.FetchFile(ctx, version, revision, filename)
+		if err == nil {
+			if err := os.WriteFile(filepath.Join(destPath, filename), content, 0644); err != nil {
+				return fmt.Errorf("failed to write file: %w", err)
+			}
+			return nil
+		}
+		if !isRetryable(err) {
+			return err
+		}
+		if attempt < maxAttempts {
+			if err := sleepContext(ctx, delay); err != nil {
+				return err
+			}
+			delay *= 2
+		}
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", maxAttempts, err)
+}
+
+// sleepContext waits for d, returning early with the context's error if it is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isRetryable reports whether a download error may succeed on a later attempt
+func isRetryable(err error) bool {
+	// Rate limits already waited as long as is reasonable
+	var mcpErr *mcperrors.MCPError
+	if errors.As(err, &mcpErr) && mcpErr.Code == mcperrors.ErrGitHubAPI {
+		return false
+	}
+
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.StatusCode == http.StatusTooManyRequests || se.StatusCode >= 500
+	}
+	// Network errors, timeouts and connections dropped mid-body
+	var ne net.Error
+	return errors.As(err, &ne) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// swapIn replaces the live version directory with the completed staging directory.
+// Directories cannot be replaced in one rename, so the live copy is moved aside
+// first; readers in the docs package wait out the moment neither copy is in place.
+// The caller holds liveMu.
+func (u *GitHubUpdater) swapIn(version string) error {
+	stagingPath := u.stagingPath(version)
+	versionPath := filepath.Join(u.basePath, version)
+	backupPath := filepath.Join(u.basePath, models.SwapBackupPrefix+version)
+
+	if err := os.Remove(filepath.Join(stagingPath, progressFile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove progress file: %w", err)
+	}
+
+	if err := os.RemoveAll(backupPath); err != nil {
+		return fmt.Errorf("failed to clear previous backup: %w", err)
+	}
+
+	hadLive := true
+	if err := os.Rename(versionPath, backupPath); err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to move current docs aside: %w", err)
+		}
+		hadLive = false
+	}
+
+	if err := os.Rename(stagingPath, versionPath); err != nil {
+		if hadLive {
+			// Put the previous docs back so the version stays readable
+			_ = os.Rename(backupPath, versionPath)
+		}
+		return fmt.Errorf("failed to move new docs into place: %w", err)
+	}
+
+	return os.RemoveAll(backupPath)
+}
+
+// loadProgress reads the progress file of a staging directory, returning nil when there is none
+func (u *GitHubUpdater) loadProgress(stagingPath string) *syncProgress {
+	data, err := os.ReadFile(filepath.Join(stagingPath, progressFile))
+	if err != nil {
+		return nil
+	}
+
+	var progress syncProgress
+	if err := json.Unmarshal(data, &progress); err != nil || progress.Completed == nil {
+		return nil
+	}
+	return &progress
+}
+
+// saveProgress writes the progress file of a staging directory
+func (u *GitHubUpdater) saveProgress(stagingPath string, progress *syncProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal progress: %w", err)
+	}
+
+	tmpPath := filepath.Join(stagingPath, progressFile+".tmp")
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write progress file: %w", err)
+	}
+	return os.Rename(tmpPath, filepath.Join(stagingPath, progressFile))
+}
@@ -0,0 +1,388 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeGitHub serves the branch, commit, tree and raw file endpoints of
+// laravel/docs with a GitHub Enterprise layout
+type fakeGitHub struct {
+	mu          sync.Mutex
+	commit      string
+	files       map[string]string
+	failures    map[string]int  // status codes returned instead of the file content
+	failOnce    map[string]bool // failures that clear after one response
+	delay       time.Duration   // how long each raw request takes
+	requests    map[string]int  // raw requests by file
+	inFlight    int
+	peak        int    // most raw requests served at once
+	notModified int    // conditional requests answered with 304
+	auth        string // Authorization header of the last request
+	limitReset  int64  // when set, API requests fail as rate limited until then
+}
+
+func newFakeGitHub(files map[string]string) *fakeGitHub {
+	return &fakeGitHub{
+		commit:   strings.Repeat("a", 40),
+		files:    files,
+		failures: make(map[string]int),
+		failOnce: make(map[string]bool),
+		requests: make(map[string]int),
+	}
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	commit := f.commit
+	f.auth = r.Header.Get("Authorization")
+	limitReset := f.limitReset
+	f.mu.Unlock()
+
+	if limitReset > 0 && strings.HasPrefix(r.URL.Path, "/api/") {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(limitReset))
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/api/v3/repos/laravel/docs/branches":
+		json.NewEncoder(w).Encode([]map[string]string{{"name": "master"}, {"name": "11.x"}, {"name": "12.x"}})
+	case r.URL.Path == "/api/v3/repos/laravel/docs/commits/12.x":
+		etag := `"` + commit + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			f.mu.Lock()
+			f.notModified++
+			f.mu.Unlock()
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		json.NewEncoder(w).Encode(map[string]string{"sha": commit})
+	case r.URL.Path == "/api/v3/repos/laravel/docs/git/trees/"+commit:
+		f.mu.Lock()
+		var tree []map[string]string
+		for name, content := range f.files {
+			tree = append(tree, map[string]string{"path": name, "type": "blob", "sha": blobSHA([]byte(content))})
+		}
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{"tree": tree})
+	case strings.HasPrefix(r.URL.Path, "/raw/laravel/docs/"):
+		// Raw files are served by branch or commit
+		_, file, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/raw/laravel/docs/"), "/")
+		f.serveFile(w, r, file)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeGitHub) serveFile(w http.ResponseWriter, r *http.Request, file string) {
+	f.mu.Lock()
+	f.requests[file]++
+	f.inFlight++
+	f.peak = max(f.peak, f.inFlight)
+	status, failing := f.failures[file]
+	if failing && f.failOnce[file] {
+		delete(f.failures, file)
+	}
+	content, exists := f.files[file]
+	delay := f.delay
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	time.Sleep(delay)
+	if failing {
+		w.WriteHeader(status)
+		return
+	}
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+	fmt.Fprint(w, content)
+}
+
+// newTestUpdater returns an updater that syncs from repo
+func newTestUpdater(t *testing.T, repo *fakeGitHub) *GitHubUpdater {
+	t.Helper()
+
+	server := httptest.NewServer(repo)
+	t.Cleanup(server.Close)
+
+	u := NewGitHubUpdater(t.TempDir())
+	u.SetSource(NewGitHubEnterpriseSource(server.URL, "laravel/docs"))
+	return u
+}
+
+func TestUpdateDocs_WorkerPool(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i < 3*syncWorkers; i++ {
+		files[fmt.Sprintf("doc%02d.md", i)] = fmt.Sprintf("# Doc %d", i)
+	}
+	repo := newFakeGitHub(files)
+	repo.delay = 20 * time.Millisecond
+	u := newTestUpdater(t, repo)
+
+	if _, err := u.UpdateDocs(context.Background(), "12.x", false); err != nil {
+		t.Fatalf("UpdateDocs failed: %v", err)
+	}
+
+	if repo.peak < 2 || repo.peak > syncWorkers {
+		t.Errorf("Expected between 2 and %d concurrent downloads, got %d", syncWorkers, repo.peak)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(u.basePath, "12.x", name))
+		if err != nil || string(data) != content {
+			t.Fatalf("Expected %s to be synced, got %q (%v)", name, data, err)
+		}
+	}
+
+	// Only the live copy is left behind
+	entries, _ := os.ReadDir(u.basePath)
+	if len(entries) != 1 || entries[0].Name() != "12.x" {
+		t.Errorf("Expected only the version directory, got %v", entries)
+	}
+}
+
+func TestUpdateDocs_RetriesAndResumes(t *testing.T) {
+	repo := newFakeGitHub(map[string]string{"routing.md": "# Routing", "mail.md": "# Mail", "queues.md": "# Queues"})
+	// A transient error is retried, a missing file is not
+	repo.failures["mail.md"] = http.StatusServiceUnavailable
+	repo.failOnce["mail.md"] = true
+	repo.failures["queues.md"] = http.StatusNotFound
+	u := newTestUpdater(t, repo)
+
+	_, err := u.UpdateDocs(context.Background(), "12.x", false)
+	if err == nil || !strings.Contains(err.Error(), "queues.md") {
+		t.Fatalf("Expected queues.md to fail the sync, got %v", err)
+	}
+	if repo.requests["mail.md"] != 2 || repo.requests["queues.md"] != 1 {
+		t.Errorf("Expected mail.md to be retried once and queues.md not at all, got %v", repo.requests)
+	}
+	if _, err := os.Stat(filepath.Join(u.basePath, "12.x")); !os.IsNotExist(err) {
+		t.Errorf("Expected no live copy after a failed sync, got %v", err)
+	}
+
+	progress := u.loadProgress(u.stagingPath("12.x"))
+	if progress == nil || !progress.Completed["routing.md"] || !progress.Completed["mail.md"] || progress.Completed["queues.md"] {
+		t.Fatalf("Expected routing.md and mail.md to be recorded as complete, got %+v", progress)
+	}
+
+	// The next run only fetches the file that failed
+	repo.mu.Lock()
+	delete(repo.failures, "queues.md")
+	repo.requests = make(map[string]int)
+	repo.mu.Unlock()
+
+	message, err := u.UpdateDocs(context.Background(), "12.x", false)
+	if err != nil {
+		t.Fatalf("UpdateDocs failed: %v", err)
+	}
+	if len(repo.requests) != 1 || repo.requests["queues.md"] != 1 {
+		t.Errorf("Expected only queues.md to be fetched, got %v", repo.requests)
+	}
+	if !strings.Contains(message, "2 resumed") {
+		t.Errorf("Expected the resumed files in the message, got %q", message)
+	}
+	if _, err := os.Stat(filepath.Join(u.basePath, "12.x", progressFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected the progress file to stay out of the live copy, got %v", err)
+	}
+}
+
+func TestUpdateDocs_NewCommitDiscardsStagedFiles(t *testing.T) {
+	repo := newFakeGitHub(map[string]string{"routing.md": "# Routing", "mail.md": "# Mail"})
+	repo.failures["mail.md"] = http.StatusNotFound
+	u := newTestUpdater(t, repo)
+
+	if _, err := u.UpdateDocs(context.Background(), "12.x", false); err == nil {
+		t.Fatal("Expected the first sync to fail")
+	}
+
+	// Files staged for an older commit may be outdated
+	repo.mu.Lock()
+	repo.commit = strings.Repeat("b", 40)
+	delete(repo.failures, "mail.md")
+	repo.requests = make(map[string]int)
+	repo.mu.Unlock()
+
+	if _, err := u.UpdateDocs(context.Background(), "12.x", false); err != nil {
+		t.Fatalf("UpdateDocs failed: %v", err)
+	}
+	if repo.requests["routing.md"] != 1 || repo.requests["mail.md"] != 1 {
+		t.Errorf("Expected every file to be fetched again, got %v", repo.requests)
+	}
+}
+
+func TestSyncDocs_Changelog(t *testing.T) {
+	repo := newFakeGitHub(map[string]string{"routing.md": "# Routing", "mail.md": "# Mail", "queues.md": "# Queues"})
+	u := newTestUpdater(t, repo)
+
+	if _, err := u.SyncDocs(context.Background(), "12.x", false, nil); err != nil {
+		t.Fatalf("SyncDocs failed: %v", err)
+	}
+
+	// A second run against the same commit has nothing to do
+	repo.requests = make(map[string]int)
+	report, err := u.SyncDocs(context.Background(), "12.x", false, nil)
+	if err != nil {
+		t.Fatalf("SyncDocs failed: %v", err)
+	}
+	if !report.UpToDate || len(repo.requests) != 0 || !strings.Contains(report.Summary(), "already up to date") {
+		t.Errorf("Expected the second run to be up to date, got %q with downloads %v", report.Summary(), repo.requests)
+	}
+
+	// The next commit adds a file, changes a blob and deletes a file
+	repo.mu.Lock()
+	repo.commit = strings.Repeat("b", 40)
+	repo.files["horizon.md"] = "# Horizon"
+	repo.files["mail.md"] = "# Mail\n\nUpdated"
+	delete(repo.files, "queues.md")
+	repo.requests = make(map[string]int)
+	repo.mu.Unlock()
+
+	report, err = u.SyncDocs(context.Background(), "12.x", false, nil)
+	if err != nil {
+		t.Fatalf("SyncDocs failed: %v", err)
+	}
+	if strings.Join(report.Added, ",") != "horizon.md" || strings.Join(report.Modified, ",") != "mail.md" ||
+		strings.Join(report.Removed, ",") != "queues.md" || report.Unchanged != 1 || len(repo.requests) != 2 {
+		t.Errorf("Expected horizon.md added, mail.md modified and queues.md removed, got %+v with downloads %v", report, repo.requests)
+	}
+	summary := report.Summary()
+	for _, want := range []string{"from aaaaaaa to bbbbbbb", "1 added, 1 modified, 1 removed, 1 unchanged files", "## Removed\n- queues.md"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected %q in summary:\n%s", want, summary)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(u.basePath, "12.x", "queues.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected the deleted queues.md to be removed locally, got %v", err)
+	}
+
+	// Forcing downloads every file again without reporting changes
+	repo.requests = make(map[string]int)
+	report, err = u.SyncDocs(context.Background(), "12.x", true, nil)
+	if err != nil {
+		t.Fatalf("SyncDocs failed: %v", err)
+	}
+	if !report.Full || report.Changed() || report.Downloaded != 3 || len(repo.requests) != 3 {
+		t.Errorf("Expected a full download of 3 unchanged files, got %+v with downloads %v", report, repo.requests)
+	}
+	if !strings.Contains(report.Summary(), "3 files downloaded (forced full download)") {
+		t.Errorf("Expected the forced download in the summary:\n%s", report.Summary())
+	}
+}
+
+func TestDownloadSingleFile_DoesNotWaitForSync(t *testing.T) {
+	repo := newFakeGitHub(map[string]string{"routing.md": "# Routing"})
+	u := newTestUpdater(t, repo)
+
+	// A long sync holds syncMu; fetching one file must not queue behind it
+	u.syncMu.Lock()
+	defer u.syncMu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := u.DownloadSingleFile(context.Background(), "12.x", "routing.md")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("DownloadSingleFile failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("DownloadSingleFile waited for the running sync")
+	}
+
+	filePath := filepath.Join(u.basePath, "12.x", "routing.md")
+	data, err := os.ReadFile(filePath)
+	if err != nil || string(data) != "# Routing" {
+		t.Fatalf("Expected routing.md in the version directory, got %q (%v)", data, err)
+	}
+
+	// The next sync can tell the file is current
+	sha, _ := gitBlobSHA(filePath)
+	if metadata := u.loadMetadata(filepath.Join(u.basePath, "12.x")); metadata == nil || metadata.Files["routing.md"] != sha {
+		t.Errorf("Expected the blob SHA of routing.md in metadata, got %+v", metadata)
+	}
+}
+
+func TestSyncDocs_CancelAndResume(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i < 4*syncWorkers; i++ {
+		files[fmt.Sprintf("doc%02d.md", i)] = fmt.Sprintf("# Doc %d", i)
+	}
+	repo := newFakeGitHub(files)
+	repo.delay = 20 * time.Millisecond
+	u := newTestUpdater(t, repo)
+
+	// Cancel once a few files are done, while other downloads are in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := u.SyncDocs(ctx, "12.x", false, func(done, total int, message string) {
+		if done >= 3 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the sync to be cancelled, got %v", err)
+	}
+
+	// Only completed files are recorded, and only those are left in staging
+	stagingPath := u.stagingPath("12.x")
+	progress := u.loadProgress(stagingPath)
+	if progress == nil || len(progress.Completed) < 3 || len(progress.Completed) == len(files) {
+		t.Fatalf("Expected some but not all files to be complete, got %+v", progress)
+	}
+	entries, err := os.ReadDir(stagingPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if name := entry.Name(); name != progressFile && !progress.Completed[name] {
+			t.Errorf("Expected cancelled %s to be removed from staging", name)
+		}
+	}
+
+	// Resuming fetches only the files that were not complete
+	repo.mu.Lock()
+	repo.requests = make(map[string]int)
+	repo.mu.Unlock()
+
+	report, err := u.SyncDocs(context.Background(), "12.x", false, nil)
+	if err != nil {
+		t.Fatalf("SyncDocs failed: %v", err)
+	}
+	for name := range progress.Completed {
+		if repo.requests[name] != 0 {
+			t.Errorf("Expected completed %s not to be fetched again", name)
+		}
+	}
+	if len(repo.requests) != len(files)-len(progress.Completed) || len(report.Added) != len(files) {
+		t.Errorf("Expected the remaining %d files to be fetched, got %v and %+v", len(files)-len(progress.Completed), repo.requests, report)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(u.basePath, "12.x", name))
+		if err != nil || string(data) != content {
+			t.Fatalf("Expected %s to be synced, got %q (%v)", name, data, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package updater
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/models"
+)
+
+// versionCacheTTL is how long the versions listed by the docs source are reused
+const versionCacheTTL = time.Hour
+
+// versionCache holds the versions last listed by the docs source
+type versionCache struct {
+	mu        sync.Mutex
+	versions  []string
+	fetchedAt time.Time
+}
+
+// RemoteVersions returns the versions the docs source offers, newest first. The
+// list is cached for versionCacheTTL, and a stale list is preferred over an
+// error when refreshing it fails.
+func (u *GitHubUpdater) RemoteVersions(ctx context.Context) ([]string, error) {
+	c := &u.versions
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.versions != nil && time.Since(c.fetchedAt) < versionCacheTTL {
+		return slices.Clone(c.versions), nil
+	}
+
+	versions, err := u.source.ListVersions(ctx)
+	if err != nil {
+		if c.versions != nil {
+			return slices.Clone(c.versions), nil
+		}
+		return nil, err
+	}
+
+	c.versions, c.fetchedAt = versions, time.Now()
+	return slices.Clone(versions), nil
+}
+
+// LocalVersions returns the versions downloaded under the docs path, newest first
+func (u *GitHubUpdater) LocalVersions() []string {
+	entries, err := os.ReadDir(u.basePath)
+	if err != nil {
+		return nil
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() && models.IsVersionName(entry.Name()) {
+			versions = append(versions, entry.Name())
+		}
+	}
+
+	models.SortVersions(versions)
+	return versions
+}
+
+// isKnownVersion reports whether version can be synced: it is supported, already
+// downloaded, or offered by the docs source
+func (u *GitHubUpdater) isKnownVersion(ctx context.Context, version string) bool {
+	if !models.IsVersionName(version) {
+		return false
+	}
+	if slices.Contains(models.SupportedVersions, version) {
+		return true
+	}
+	if info, err := os.Stat(filepath.Join(u.basePath, version)); err == nil && info.IsDir() {
+		return true
+	}
+
+	remote, err := u.RemoteVersions(ctx)
+	return err == nil && slices.Contains(remote, version)
+}
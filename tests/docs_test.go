@@ -1,11 +1,16 @@
 package docs
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/izzamoe/laravel-mcp-companion-go/internal/docs"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/models"
 )
 
 func TestManager_ListDocs(t *testing.T) {
@@ -29,7 +34,7 @@ func TestManager_ListDocs(t *testing.T) {
 	manager := docs.NewManager(tmpDir, "12.x")
 
 	// List docs
-	files, err := manager.ListDocs("12.x")
+	files, err := manager.ListDocs(context.Background(), "12.x")
 	if err != nil {
 		t.Fatalf("ListDocs failed: %v", err)
 	}
@@ -59,7 +64,7 @@ func TestManager_ReadDoc(t *testing.T) {
 	manager := docs.NewManager(tmpDir, "12.x")
 
 	// Read doc
-	content, err := manager.ReadDoc("12.x", "routing.md")
+	content, err := manager.ReadDoc(context.Background(), "12.x", "routing.md")
 	if err != nil {
 		t.Fatalf("ReadDoc failed: %v", err)
 	}
@@ -96,10 +101,11 @@ func TestManager_SearchDocs(t *testing.T) {
 	manager := docs.NewManager(tmpDir, "12.x")
 
 	// Search for "HTTP"
-	results, err := manager.SearchDocs("HTTP", "12.x")
+	hits, err := manager.Search(context.Background(), "HTTP", "12.x", 0)
 	if err != nil {
-		t.Fatalf("SearchDocs failed: %v", err)
+		t.Fatalf("Search failed: %v", err)
 	}
+	results := docs.FormatSearchResults("HTTP", "12.x", hits)
 
 	// Should return results string
 	if len(results) == 0 {
@@ -112,6 +118,428 @@ func TestManager_SearchDocs(t *testing.T) {
 	}
 }
 
+func TestManager_SearchRanking(t *testing.T) {
+	// Create temporary docs directory
+	tmpDir := t.TempDir()
+	versionDir := filepath.Join(tmpDir, "12.x")
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"queues.md":  "# Queues\n\n## Dispatching Jobs\n\nDispatch jobs onto the queue. Queued jobs run later.\n\n## Job Batching\n\nBatch several jobs.",
+		"events.md":  "# Events\n\nListeners may be queued on the queue connection.",
+		"routing.md": "# Routing\n\nDefine routes for your application.",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(versionDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager := docs.NewManager(tmpDir, "12.x")
+
+	hits, err := manager.Search(context.Background(), "queue jobs", "12.x", 0)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(hits) == 0 {
+		t.Fatal("Expected hits, got none")
+	}
+
+	// The dedicated section should outrank a passing mention
+	if hits[0].File != "queues.md" || hits[0].Section != "Dispatching Jobs" {
+		t.Errorf("Expected queues.md#Dispatching Jobs first, got %s#%s", hits[0].File, hits[0].Section)
+	}
+
+	for _, hit := range hits {
+		if hit.File == "routing.md" {
+			t.Errorf("routing.md should not match 'queue jobs'")
+		}
+	}
+
+	// New files are picked up after a refresh
+	extra := filepath.Join(versionDir, "horizon.md")
+	if err := os.WriteFile(extra, []byte("# Horizon\n\nA dashboard for Redis queues."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.RefreshIndex(context.Background(), "12.x"); err != nil {
+		t.Fatalf("RefreshIndex failed: %v", err)
+	}
+
+	hits, err = manager.Search(context.Background(), "horizon", "12.x", 0)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(hits) == 0 || hits[0].File != "horizon.md" {
+		t.Errorf("Expected horizon.md after refresh, got %v", hits)
+	}
+}
+
+func TestParseSections(t *testing.T) {
+	content := "# Routing\n\n- [Basic Routing](#basic-routing)\n\n<a name=\"basic-routing\"></a>\n## Basic Routing\n\nRoutes accept a URI.\n\n```bash\n# not a heading\nphp artisan route:list\n```\n\n### Redirect Routes\n\nUse redirect."
+
+	sections := docs.ParseSections(content)
+	if len(sections) != 3 {
+		t.Fatalf("Expected 3 sections, got %d: %+v", len(sections), sections)
+	}
+
+	expected := []struct {
+		title  string
+		level  int
+		anchor string
+	}{
+		{"Routing", 1, "routing"},
+		{"Basic Routing", 2, "basic-routing"},
+		{"Redirect Routes", 3, "redirect-routes"},
+	}
+	for i, want := range expected {
+		got := sections[i]
+		if got.Title != want.title || got.Level != want.level || got.Anchor != want.anchor {
+			t.Errorf("Section %d: expected %+v, got %s/%d/%s", i, want, got.Title, got.Level, got.Anchor)
+		}
+	}
+
+	if contains(sections[0].Body, "<a name") {
+		t.Error("Anchor tag should not be part of the preceding section")
+	}
+	if !contains(sections[1].Body, "# not a heading") {
+		t.Error("Code fence content should stay in its section")
+	}
+}
+
+func TestManager_SearchContextKeepsCodeFences(t *testing.T) {
+	tmpDir := t.TempDir()
+	versionDir := filepath.Join(tmpDir, "12.x")
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	content := "# Queues\n\n<a name=\"dispatching-jobs\"></a>\n## Dispatching Jobs\n\nUse the dispatch method:\n\n```php\nProcessPodcast::dispatch($podcast);\n```\n"
+	if err := os.WriteFile(filepath.Join(versionDir, "queues.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := docs.NewManager(tmpDir, "12.x")
+
+	results, err := manager.SearchContext(context.Background(), "ProcessPodcast", "12.x", 10)
+	if err != nil {
+		t.Fatalf("SearchContext failed: %v", err)
+	}
+	result := docs.FormatContextResults("ProcessPodcast", "12.x", results)
+
+	if !contains(result, "queues.md#dispatching-jobs - Dispatching Jobs") {
+		t.Errorf("Expected section reference in results:\n%s", result)
+	}
+	if !contains(result, "```php\nProcessPodcast::dispatch($podcast);\n```") {
+		t.Errorf("Expected intact code block in results:\n%s", result)
+	}
+}
+
+func TestManager_ReadSection(t *testing.T) {
+	tmpDir := t.TempDir()
+	versionDir := filepath.Join(tmpDir, "12.x")
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	content := "# Routing\n\nIntro.\n\n<a name=\"basic-routing\"></a>\n## Basic Routing\n\nRoutes accept a URI.\n\n<a name=\"redirect-routes\"></a>\n### Redirect Routes\n\nUse redirect.\n\n<a name=\"route-parameters\"></a>\n## Route Parameters\n\nCapture segments."
+	if err := os.WriteFile(filepath.Join(versionDir, "routing.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := docs.NewManager(tmpDir, "12.x")
+
+	// Heading text, slug and anchor all resolve to the same section
+	for _, ref := range []string{"Basic Routing", "basic routing", "#basic-routing"} {
+		section, err := manager.GetSection(context.Background(), "12.x", "routing.md", ref, false)
+		if err != nil {
+			t.Fatalf("GetSection(%q) failed: %v", ref, err)
+		}
+		if section.Section.Anchor != "basic-routing" {
+			t.Errorf("GetSection(%q) resolved to %s", ref, section.Section.Anchor)
+		}
+		if contains(section.Content, "Use redirect.") {
+			t.Errorf("GetSection(%q) should exclude subsections", ref)
+		}
+	}
+
+	section, err := manager.GetSection(context.Background(), "12.x", "routing.md", "basic-routing", true)
+	if err != nil {
+		t.Fatalf("GetSection failed: %v", err)
+	}
+	if !contains(section.Content, "Use redirect.") || contains(section.Content, "Capture segments.") {
+		t.Errorf("Unexpected content with subsections:\n%s", section.Content)
+	}
+
+	section, err = manager.GetSection(context.Background(), "12.x", "routing.md", "redirect-routes", false)
+	if err != nil {
+		t.Fatalf("GetSection failed: %v", err)
+	}
+	result := docs.FormatSection("12.x", "routing.md", section)
+	if !contains(result, "Routing > Basic Routing > Redirect Routes") {
+		t.Errorf("Expected breadcrumb in result:\n%s", result)
+	}
+
+	if _, err := manager.GetSection(context.Background(), "12.x", "routing.md", "missing", false); err == nil {
+		t.Error("Expected error for unknown section")
+	}
+}
+
+func TestManager_SearchAllVersions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	docsByVersion := map[string]string{
+		"10.x": "# Queues\n\n## Batching\n\nBatch queue jobs together.\n\n## Legacy Workers\n\nOld queue workers.",
+		"11.x": "# Queues\n\n## Batching\n\nBatch queue jobs together.\n\n## Legacy Workers\n\nOld queue workers.",
+		"12.x": "# Queues\n\n## Batching\n\nBatch queue jobs together, now with progress.",
+	}
+	for version, content := range docsByVersion {
+		versionDir := filepath.Join(tmpDir, version)
+		if err := os.MkdirAll(versionDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(versionDir, "queues.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager := docs.NewManager(tmpDir, "12.x")
+
+	results, err := manager.SearchAllVersions(context.Background(), "queue")
+	if err != nil {
+		t.Fatalf("SearchAllVersions failed: %v", err)
+	}
+
+	if len(results) != 3 || results[0].Version != "12.x" || results[2].Version != "10.x" {
+		t.Fatalf("Expected results for 12.x, 11.x, 10.x, got %+v", results)
+	}
+
+	// 10.x passages are identical in 11.x, so they are reported under 11.x only
+	if len(results[2].Passages) != 0 {
+		t.Errorf("Expected no passages unique to 10.x, got %+v", results[2].Passages)
+	}
+
+	found := map[string]docs.Passage{}
+	for _, vr := range results {
+		for _, p := range vr.Passages {
+			found[p.Hit.Version+":"+p.Hit.Anchor] = p
+		}
+	}
+
+	batching, ok := found["12.x:batching"]
+	if !ok || batching.FirstChange != "changed" || batching.FirstVersion != "12.x" {
+		t.Errorf("Expected 12.x batching to be changed in 12.x, got %+v", batching)
+	}
+
+	legacy, ok := found["11.x:legacy-workers"]
+	if !ok || legacy.GoneChange != "removed" || legacy.GoneIn != "12.x" || len(legacy.Versions) != 2 {
+		t.Errorf("Expected legacy workers in 11.x and 10.x, removed in 12.x, got %+v", legacy)
+	}
+
+	text := docs.FormatAllVersionsResults("queue", results)
+	if !contains(text, "## Laravel 10.x") || !contains(text, "removed in 12.x") {
+		t.Errorf("Expected multi-version output, got:\n%s", text)
+	}
+}
+
+func TestManager_DiffDoc(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	docsByVersion := map[string]string{
+		"10.x": "# Queues\n\n## Dispatching\n\nDispatch a job:\n\n```php\nProcessPodcast::dispatch($podcast);\n```\n\n## Old Feature\n\nGone soon.\n\n## Stable\n\nNever changes.",
+		"12.x": "# Queues\n\n## Dispatching\n\nDispatch a job:\n\n```php\nProcessPodcast::dispatch($podcast)->onQueue('audio');\n```\n\n## Stable\n\nNever changes.\n\n## Batching\n\nNew in 12.",
+	}
+	for version, content := range docsByVersion {
+		versionDir := filepath.Join(tmpDir, version)
+		if err := os.MkdirAll(versionDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(versionDir, "queues.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager := docs.NewManager(tmpDir, "12.x")
+
+	diff, err := manager.CompareDoc(context.Background(), "queues.md", "10.x", "12.x")
+	if err != nil {
+		t.Fatalf("CompareDoc failed: %v", err)
+	}
+
+	if len(diff.Added) != 1 || diff.Added[0].Anchor != "batching" {
+		t.Errorf("Expected batching added, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Anchor != "old-feature" {
+		t.Errorf("Expected old-feature removed, got %+v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Anchor != "dispatching" {
+		t.Fatalf("Expected dispatching changed, got %+v", diff.Changed)
+	}
+	if diff.Changed[0].ProseChanged {
+		t.Error("Only the code block changed in dispatching")
+	}
+	if len(diff.Changed[0].CodeDiffs) != 1 {
+		t.Fatalf("Expected one code diff, got %d", len(diff.Changed[0].CodeDiffs))
+	}
+	codeDiff := diff.Changed[0].CodeDiffs[0]
+	if !contains(codeDiff, "-ProcessPodcast::dispatch($podcast);") || !contains(codeDiff, "+ProcessPodcast::dispatch($podcast)->onQueue('audio');") {
+		t.Errorf("Unexpected code diff:\n%s", codeDiff)
+	}
+
+	if _, err := manager.CompareDoc(context.Background(), "queues.md", "10.x", "99.x"); err == nil {
+		t.Error("Expected error for unsupported version")
+	}
+}
+
+func TestManager_DiffDocInsertedCodeBlock(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	oldSection := "# Queues\n\n## Dispatching\n\nDispatch a job:\n\n```php\nProcessPodcast::dispatch($podcast);\n```\n\nDelay it:\n\n```php\nProcessPodcast::dispatch($podcast)->delay(now()->addMinutes(10));\n```"
+	newSection := "# Queues\n\n## Dispatching\n\nDispatch a job:\n\n```php\nProcessPodcast::dispatch($podcast);\n```\n\nPick a queue:\n\n```php\nProcessPodcast::dispatch($podcast)->onQueue('audio');\n```\n\nDelay it:\n\n```php\nProcessPodcast::dispatch($podcast)->delay(now()->addMinutes(15));\n```"
+	for version, content := range map[string]string{"11.x": oldSection, "12.x": newSection} {
+		versionDir := filepath.Join(tmpDir, version)
+		if err := os.MkdirAll(versionDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(versionDir, "queues.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager := docs.NewManager(tmpDir, "12.x")
+
+	diff, err := manager.CompareDoc(context.Background(), "queues.md", "11.x", "12.x")
+	if err != nil {
+		t.Fatalf("CompareDoc failed: %v", err)
+	}
+	if len(diff.Changed) != 1 {
+		t.Fatalf("Expected dispatching changed, got %+v", diff.Changed)
+	}
+
+	// The inserted block is new and the delay block is diffed against its old version
+	codeDiffs := diff.Changed[0].CodeDiffs
+	if len(codeDiffs) != 2 {
+		t.Fatalf("Expected two code diffs, got %d:\n%s", len(codeDiffs), strings.Join(codeDiffs, "\n"))
+	}
+	if !contains(codeDiffs[0], "+ProcessPodcast::dispatch($podcast)->onQueue('audio');") || contains(codeDiffs[0], "\n-") {
+		t.Errorf("Expected the inserted block as an addition:\n%s", codeDiffs[0])
+	}
+	if !contains(codeDiffs[1], "-ProcessPodcast::dispatch($podcast)->delay(now()->addMinutes(10));") ||
+		!contains(codeDiffs[1], "+ProcessPodcast::dispatch($podcast)->delay(now()->addMinutes(15));") {
+		t.Errorf("Expected the delay block diffed against its old version:\n%s", codeDiffs[1])
+	}
+}
+
+func TestManager_DiffDocAddedAndRemovedCodeBlocks(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	oldSection := "# Queues\n\n## Dispatching\n\nConfigure the connection:\n\n```php\n'connections' => ['redis' => []],\n'default' => 'redis',\n```"
+	newSection := "# Queues\n\n## Dispatching\n\nDispatch a job:\n\n```shell\nphp artisan queue:work\n```"
+	for version, content := range map[string]string{"11.x": oldSection, "12.x": newSection} {
+		versionDir := filepath.Join(tmpDir, version)
+		if err := os.MkdirAll(versionDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(versionDir, "queues.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager := docs.NewManager(tmpDir, "12.x")
+
+	diff, err := manager.CompareDoc(context.Background(), "queues.md", "11.x", "12.x")
+	if err != nil {
+		t.Fatalf("CompareDoc failed: %v", err)
+	}
+	if len(diff.Changed) != 1 {
+		t.Fatalf("Expected dispatching changed, got %+v", diff.Changed)
+	}
+
+	// The blocks share nothing, so one is added and the other removed, each with an empty side
+	codeDiffs := diff.Changed[0].CodeDiffs
+	if len(codeDiffs) != 2 {
+		t.Fatalf("Expected two code diffs, got %d:\n%s", len(codeDiffs), strings.Join(codeDiffs, "\n"))
+	}
+	if !contains(codeDiffs[0], "@@ -0,0 +1,1 @@\n+php artisan queue:work") {
+		t.Errorf("Expected the added block to start at -0,0:\n%s", codeDiffs[0])
+	}
+	if !contains(codeDiffs[1], "@@ -1,2 +0,0 @@\n-'connections' => ['redis' => []],") {
+		t.Errorf("Expected the removed block to end at +0,0:\n%s", codeDiffs[1])
+	}
+}
+
+func TestManager_PlanUpgrade(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	guides := map[string]string{
+		"11.x": "# Upgrade Guide\n\n<a name=\"upgrade-11.0\"></a>\n## Upgrading To 11.0 From 10.x\n\n<a name=\"queues\"></a>\n### Queues\n\n<a name=\"job-batching\"></a>\n#### Job Batching\n\n**Likelihood Of Impact: High**\n\nBatches changed.\n\n<a name=\"cache\"></a>\n### Cache\n\n<a name=\"cache-key-prefixes\"></a>\n#### Cache Key Prefixes\n\n**Likelihood Of Impact: Very Low**\n\nPrefixes changed.",
+		"12.x": "# Upgrade Guide\n\n<a name=\"upgrade-12.0\"></a>\n## Upgrading To 12.0 From 11.x\n\n<a name=\"updating-dependencies\"></a>\n### Updating Dependencies\n\n**Likelihood Of Impact: High**\n\nUpdate composer.json.\n\n<a name=\"queue\"></a>\n### Queue\n\n<a name=\"failed-jobs\"></a>\n#### Failed Jobs\n\n**Likelihood Of Impact: Low**\n\nFailed jobs table.\n\n<a name=\"queue-names\"></a>\n#### Queue Names\n\n**Likelihood Of Impact: Depends On Driver**\n\nNames are no longer prefixed.",
+	}
+	for version, content := range guides {
+		versionDir := filepath.Join(tmpDir, version)
+		if err := os.MkdirAll(versionDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(versionDir, "upgrade.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager := docs.NewManager(tmpDir, "12.x")
+
+	plan, err := manager.PlanUpgrade(context.Background(), "10.x", "12.x", docs.UpgradeFilter{})
+	if err != nil {
+		t.Fatalf("PlanUpgrade failed: %v", err)
+	}
+	if len(plan.Missing) != 0 {
+		t.Errorf("Expected no missing guides, got %v", plan.Missing)
+	}
+	items := plan.Items
+	if len(items) != 5 {
+		t.Fatalf("Expected 5 items across both guides, got %d: %+v", len(items), items)
+	}
+	if items[0].TargetVersion != "11.x" || items[0].Component != "Queues" || items[0].Impact != "high" {
+		t.Errorf("Unexpected first item: %+v", items[0])
+	}
+
+	// "queues" matches both the "Queues" and "Queue" components
+	plan, err = manager.PlanUpgrade(context.Background(), "10.x", "12.x", docs.UpgradeFilter{Component: "queues"})
+	if err != nil {
+		t.Fatalf("PlanUpgrade failed: %v", err)
+	}
+	items = plan.Items
+	if len(items) != 3 {
+		t.Errorf("Expected 3 queue items, got %+v", items)
+	}
+
+	plan, err = manager.PlanUpgrade(context.Background(), "10.x", "12.x", docs.UpgradeFilter{MinImpact: "high", Component: "queues"})
+	if err != nil {
+		t.Fatalf("PlanUpgrade failed: %v", err)
+	}
+	items = plan.Items
+	if len(items) != 1 || items[0].Anchor != "job-batching" {
+		t.Errorf("Expected only job-batching, got %+v", items)
+	}
+
+	// Queue Names has no known impact level, so it cannot meet any minimum
+	plan, err = manager.PlanUpgrade(context.Background(), "10.x", "12.x", docs.UpgradeFilter{MinImpact: "optional", Component: "queues"})
+	if err != nil {
+		t.Fatalf("PlanUpgrade failed: %v", err)
+	}
+	items = plan.Items
+	if len(items) != 2 {
+		t.Errorf("Expected the unclassified item to be dropped, got %+v", items)
+	}
+
+	if _, err := manager.PlanUpgrade(context.Background(), "12.x", "10.x", docs.UpgradeFilter{}); err == nil {
+		t.Error("Expected error when upgrading backwards")
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) &&
@@ -127,3 +555,162 @@ func findInString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestManager_RejectsVersionOutsideDocsPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	docsPath := filepath.Join(tmpDir, "docs")
+	if err := os.MkdirAll(docsPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// A markdown file outside the docs directory must not become searchable
+	if err := os.WriteFile(filepath.Join(tmpDir, "secret.md"), []byte("# Secret\n\npassword"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := docs.NewManager(docsPath, "12.x")
+	ctx := context.Background()
+
+	if hits, err := manager.Search(ctx, "password", "..", 0); err == nil {
+		t.Errorf("Expected Search to reject a version outside the docs path, got %d hits", len(hits))
+	}
+	if files, err := manager.ListDocs(ctx, ".."); err == nil {
+		t.Errorf("Expected ListDocs to reject a version outside the docs path, got %v", files)
+	}
+}
+
+func TestManager_SearchContextNegativeLength(t *testing.T) {
+	tmpDir := t.TempDir()
+	versionDir := filepath.Join(tmpDir, "12.x")
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A paragraph longer than the context so it has to be trimmed around the match
+	paragraph := strings.Repeat("Queued jobs run in the background. ", 10) + "Horizon supervises them. " + strings.Repeat("Workers restart after deploys. ", 10)
+	if err := os.WriteFile(filepath.Join(versionDir, "queues.md"), []byte("# Queues\n\n"+paragraph+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := docs.NewManager(tmpDir, "12.x")
+
+	results, err := manager.SearchContext(context.Background(), "Horizon", "12.x", -50)
+	if err != nil {
+		t.Fatalf("SearchContext failed: %v", err)
+	}
+	result := docs.FormatContextResults("Horizon", "12.x", results)
+	if !contains(result, "Horizon") {
+		t.Errorf("Expected the match in results:\n%s", result)
+	}
+}
+
+func TestManager_SearchContextWithoutLiteralMatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	versionDir := filepath.Join(tmpDir, "12.x")
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// "policies" only matches the body through stemming
+	content := "# Authorization\n\n<a name=\"writing-gates\"></a>\n## Writing Gates\n\nEach policy authorizes actions on one model.\n"
+	if err := os.WriteFile(filepath.Join(versionDir, "authorization.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := docs.NewManager(tmpDir, "12.x")
+
+	for _, version := range []string{"12.x", ""} {
+		results, err := manager.SearchContext(context.Background(), "policies", version, 100)
+		if err != nil {
+			t.Fatalf("SearchContext(%q) failed: %v", version, err)
+		}
+		result := docs.FormatContextResults("policies", version, results)
+		if !contains(result, "authorization.md#writing-gates") || !contains(result, "Each policy authorizes actions on one model.") {
+			t.Errorf("Expected the opening paragraph of the section for version %q:\n%s", version, result)
+		}
+	}
+}
+
+func TestManager_ReadDocDuringSwap(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// An update has moved the live copy aside and is about to rename the new one in
+	backupDir := filepath.Join(tmpDir, models.SwapBackupPrefix+"12.x")
+	stagingDir := filepath.Join(tmpDir, ".staging-12.x")
+	for dir, content := range map[string]string{backupDir: "# Old", stagingDir: "# New"} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "routing.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		if err := os.Rename(stagingDir, filepath.Join(tmpDir, "12.x")); err != nil {
+			done <- err
+			return
+		}
+		done <- os.RemoveAll(backupDir)
+	}()
+
+	manager := docs.NewManager(tmpDir, "12.x")
+
+	content, err := manager.ReadDoc(context.Background(), "12.x", "routing.md")
+	if err != nil || content != "# New" {
+		t.Errorf("Expected ReadDoc to wait for the new copy, got %q (%v)", content, err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a swap in progress a missing version fails right away
+	if _, err := manager.ListDocs(context.Background(), "11.x"); err == nil {
+		t.Error("Expected ListDocs to fail for a missing version")
+	}
+}
+
+func TestManager_VersionsIncludesNewDirectories(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"13.x", ".staging-13.x", "notes"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions := docs.NewManager(tmpDir, "12.x").Versions()
+
+	if len(versions) != 8 || versions[0] != "13.x" {
+		t.Errorf("Expected 13.x followed by the supported versions, got %v", versions)
+	}
+}
+
+func TestManager_CancelledSearch(t *testing.T) {
+	tmpDir := t.TempDir()
+	versionDir := filepath.Join(tmpDir, "12.x")
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(versionDir, "queues.md"), []byte("# Queues\n\nDispatch jobs to a queue."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := docs.NewManager(tmpDir, "12.x")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := manager.Search(ctx, "queue", "12.x", 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a cancelled search, got %v", err)
+	}
+
+	// The interrupted scan must not leave an empty index behind
+	hits, err := manager.Search(context.Background(), "queue", "12.x", 0)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(hits) != 1 {
+		t.Errorf("Expected 1 hit after the cancelled search, got %d", len(hits))
+	}
+}
@@ -0,0 +1,436 @@
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/docs"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/external"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/server"
+)
+
+func TestWebScraper_FetchResourceConvertsHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><title>Queues</title><script>track()</script></head><body>
+<nav><a href="/">Home</a></nav>
+<main><article>
+<h1>Queue Workers</h1>
+<p>Forge manages <strong>queue workers</strong> for your <a href="/docs/sites">sites</a> and restarts them on every deployment.</p>
+<ul><li>Connection<ul><li>redis</li></ul></li><li>Processes</li></ul>
+<pre><code class="language-bash">php artisan queue:work --tries=3</code></pre>
+<table><tr><th>Option</th><th>Default</th></tr><tr><td>tries</td><td>3</td></tr></table>
+</article></main>
+<footer>Copyright</footer></body></html>`)
+	}))
+	defer server.Close()
+
+	content, err := external.NewWebScraper().FetchResource(context.Background(), server.URL+"/docs/queues")
+	if err != nil {
+		t.Fatalf("FetchResource failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"# Queue Workers",
+		"**queue workers**",
+		"[sites](" + server.URL + "/docs/sites)",
+		"- Connection\n  - redis\n- Processes",
+		"```bash\nphp artisan queue:work --tries=3\n```",
+		"| Option | Default |\n| --- | --- |\n| tries | 3 |",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected %q in converted page:\n%s", want, content)
+		}
+	}
+	for _, unwanted := range []string{"<", "track()", "Home", "Copyright"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("Expected %q to be dropped from converted page:\n%s", unwanted, content)
+		}
+	}
+}
+
+func TestWebScraper_CrawlFollowsLinksAndRobots(t *testing.T) {
+	links := map[string][]string{
+		"/docs":             {"/docs/servers", "/docs/private", "/blog", "/docs/logo.png"},
+		"/docs/servers":     {"/docs/servers/php", "/docs"},
+		"/docs/servers/php": {"/docs/too-deep"},
+	}
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprint(w, "User-agent: *\nDisallow: /docs/private\n")
+			return
+		case "/sitemap.xml":
+			fmt.Fprint(w, `<urlset><url><loc>http://`+r.Host+`/docs/sites</loc></url></urlset>`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<html><body><nav>")
+		for _, link := range links[r.URL.Path] {
+			fmt.Fprintf(w, `<a href="%s">link</a>`, link)
+		}
+		fmt.Fprintf(w, "</nav><article><h1>Page %s</h1><p>Documentation.</p></article></body></html>", r.URL.Path)
+	}))
+	defer server.Close()
+
+	rules := external.CrawlRules{MaxDepth: 2, MaxPages: 10}
+	pages, err := external.NewWebScraper().Crawl(context.Background(), server.URL+"/docs", rules)
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+
+	var got []string
+	for _, page := range pages {
+		got = append(got, strings.TrimPrefix(page.URL, server.URL))
+		if page.Title != "Page "+strings.TrimPrefix(page.URL, server.URL) {
+			t.Errorf("Unexpected title %q for %s", page.Title, page.URL)
+		}
+	}
+	want := "/docs /docs/sites /docs/servers /docs/servers/php"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Expected pages %q, got %q", want, strings.Join(got, " "))
+	}
+
+	for _, path := range requested {
+		if path == "/docs/private" || path == "/blog" || path == "/docs/too-deep" || path == "/docs/logo.png" {
+			t.Errorf("Crawler should not have requested %s", path)
+		}
+	}
+
+	rules.MaxPages = 2
+	pages, err = external.NewWebScraper().Crawl(context.Background(), server.URL+"/docs", rules)
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	if len(pages) != 2 {
+		t.Errorf("Expected the page budget to stop the crawl at 2 pages, got %d", len(pages))
+	}
+}
+
+func TestServer_ExternalPageTools(t *testing.T) {
+	cacheDir := t.TempDir()
+	manager := external.NewExternalManager(cacheDir)
+
+	srv := server.NewServer(docs.NewManager(t.TempDir(), "12.x"))
+	srv.RegisterExternalServiceTools(manager)
+	session := connectClient(t, srv)
+
+	list := map[string]any{"service": "forge"}
+	if result := callTool(t, session, "list_external_laravel_docs", list, nil); !result.IsError || !strings.Contains(resultText(result), "not cached") {
+		t.Errorf("Expected listing an uncached service to fail, got %q", resultText(result))
+	}
+
+	// The cache a crawl of the Forge docs leaves behind
+	index := []external.PageInfo{
+		{ID: "docs", Title: "Forge", URL: "https://forge.laravel.com/docs", File: "docs.md",
+			Sections: []external.PageSection{{Title: "Forge", Level: 1, Anchor: "forge"}}},
+		{ID: "docs-servers", Title: "Servers", URL: "https://forge.laravel.com/docs/servers", File: "docs-servers.md",
+			Sections: []external.PageSection{
+				{Title: "Servers", Level: 1, Anchor: "servers"},
+				{Title: "Providers", Level: 2, Anchor: "providers"},
+				{Title: "Regions", Level: 3, Anchor: "regions"},
+				{Title: "Timezones", Level: 2, Anchor: "timezones"},
+			}},
+	}
+	files := map[string]string{
+		"docs.md":         "---\ntitle: Forge\nurl: https://forge.laravel.com/docs\n---\n\n# Forge\n\nForge provisions servers.\n",
+		"docs-servers.md": "---\ntitle: Servers\nurl: https://forge.laravel.com/docs/servers\n---\n\n# Servers\n\n## Providers\n\nForge supports several providers.\n\n### Regions\n\nPick a region close to your users.\n\n## Timezones\n\nServers use UTC.\n",
+	}
+	serviceDir := filepath.Join(cacheDir, "forge")
+	if err := os.MkdirAll(serviceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["index.json"] = string(data)
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(serviceDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var pages server.ExternalPagesOutput
+	result := callTool(t, session, "list_external_laravel_docs", list, &pages)
+	if result.IsError || len(pages.Pages) != 2 || pages.Pages[1].ID != "docs-servers" || len(pages.Pages[1].Headings) != 4 {
+		t.Fatalf("Unexpected page list %+v: %s", pages, resultText(result))
+	}
+	// Only second level headings are listed in the Markdown
+	if text := resultText(result); !strings.Contains(text, "  - Timezones (#timezones)") || strings.Contains(text, "Regions") {
+		t.Errorf("Unexpected page list Markdown:\n%s", text)
+	}
+
+	// Pages are found by ID, URL, URL path or title; the front matter is not returned
+	for _, ref := range []string{"docs-servers", "docs-servers.md", "https://forge.laravel.com/docs/servers", "/docs/servers/", "servers"} {
+		var doc server.ExternalDocOutput
+		result := callTool(t, session, "read_external_laravel_doc", map[string]any{"service": "forge", "page": ref}, &doc)
+		if result.IsError || doc.Page != "docs-servers" || !strings.HasPrefix(doc.Content, "# Servers") {
+			t.Errorf("Expected %q to read the whole servers page, got %+v: %s", ref, doc, resultText(result))
+		}
+	}
+
+	var doc server.ExternalDocOutput
+	result = callTool(t, session, "read_external_laravel_doc", map[string]any{
+		"service":             "forge",
+		"page":                "docs-servers",
+		"section":             "Providers",
+		"include_subsections": true,
+	}, &doc)
+	if result.IsError || doc.Section != "Providers" || doc.Anchor != "providers" || doc.Content != "## Providers\n\nForge supports several providers.\n\n### Regions\n\nPick a region close to your users." {
+		t.Errorf("Unexpected section %+v: %s", doc, resultText(result))
+	}
+
+	for _, tc := range []struct {
+		page, section, want string
+	}{
+		{"docs-sites", "", "page not found: docs-sites in forge"},
+		{"docs-servers", "Monitoring", "section not found: Monitoring in docs-servers"},
+	} {
+		result := callTool(t, session, "read_external_laravel_doc", map[string]any{"service": "forge", "page": tc.page, "section": tc.section}, nil)
+		if !result.IsError || !strings.Contains(resultText(result), tc.want) {
+			t.Errorf("Expected %q, got %q", tc.want, resultText(result))
+		}
+	}
+}
+
+func TestExternalManager_ConfiguredServicePages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/handbook":
+			fmt.Fprint(w, `<article><h1>Handbook</h1><p>The handbook explains how the team ships code, starting with <a href="/handbook/deploy">deploying</a>.</p></article>`)
+		case "/handbook/deploy":
+			fmt.Fprint(w, `<article><h1>Deploying</h1><h2>Checklist</h2><p>Run the migrations first.</p><h2>Rollback</h2><p>Redeploy the previous release.</p></article>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "services.yaml")
+	config := fmt.Sprintf("services:\n  - id: handbook\n    name: Team Handbook\n    url: %s/handbook\n    crawl: {max_depth: 1, delay: 0s}\n", server.URL)
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write services config: %v", err)
+	}
+
+	services, err := external.LoadServices(configPath)
+	if err != nil {
+		t.Fatalf("LoadServices failed: %v", err)
+	}
+
+	manager := external.NewExternalManager(t.TempDir())
+	manager.SetServices(services)
+
+	ctx := context.Background()
+	if _, err := manager.UpdateService(ctx, "forge", false); err == nil || !strings.Contains(err.Error(), "Available services: handbook") {
+		t.Errorf("Expected services missing from the config to be unknown, got %v", err)
+	}
+	if _, err := manager.UpdateService(ctx, "handbook", false); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+
+	pages, err := manager.ListPages(ctx, "handbook")
+	if err != nil {
+		t.Fatalf("ListPages failed: %v", err)
+	}
+	if len(pages) != 2 || pages[1].ID != "handbook-deploy" || pages[1].Title != "Deploying" || len(pages[1].Sections) != 3 {
+		t.Fatalf("Unexpected page index: %+v", pages)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := manager.ListPages(cancelled, "handbook"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected ListPages to honor a cancelled context, got %v", err)
+	}
+
+	page, err := manager.ReadPage(ctx, "handbook", server.URL+"/handbook/deploy", "checklist", false)
+	if err != nil {
+		t.Fatalf("ReadPage failed: %v", err)
+	}
+	if page.Content != "## Checklist\n\nRun the migrations first." {
+		t.Errorf("Expected only the Checklist section, got %q", page.Content)
+	}
+
+	overview, err := manager.Overview(ctx, "handbook")
+	if err != nil {
+		t.Fatalf("Overview failed: %v", err)
+	}
+	if overview.Metadata.Pages != 2 || overview.Metadata.CachedAt.IsZero() {
+		t.Errorf("Expected metadata of the crawl, got %+v", overview.Metadata)
+	}
+	if overview.Intro != "The handbook explains how the team ships code, starting with deploying." {
+		t.Errorf("Unexpected intro %q", overview.Intro)
+	}
+	if strings.Join(overview.Sections, ",") != "Deploying" || strings.Join(overview.Features, ",") != "Checklist,Rollback" {
+		t.Errorf("Unexpected sections %q or features %q", overview.Sections, overview.Features)
+	}
+}
+
+func TestExternalManager_CrawlPathPrefixes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/docs":
+			fmt.Fprint(w, `<article><h1>Docs</h1><p>See <a href="/docs/guides/deploy">deploying</a>, <a href="/docs/guides/legacy">legacy</a> and <a href="/docs/changelog">changes</a>.</p></article>`)
+		case "/docs/guides/deploy", "/docs/guides/legacy", "/docs/changelog":
+			fmt.Fprintf(w, "<article><h1>%s</h1><p>Page body.</p></article>", r.URL.Path)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "services.yaml")
+	config := fmt.Sprintf("services:\n  - id: docs\n    url: %s/docs\n    crawl: {max_depth: 1, delay: 0s, include: [/docs/guides], exclude: [/docs/guides/legacy]}\n", server.URL)
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write services config: %v", err)
+	}
+
+	services, err := external.LoadServices(configPath)
+	if err != nil {
+		t.Fatalf("LoadServices failed: %v", err)
+	}
+	manager := external.NewExternalManager(t.TempDir())
+	manager.SetServices(services)
+
+	ctx := context.Background()
+	if _, err := manager.UpdateService(ctx, "docs", false); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	pages, err := manager.ListPages(ctx, "docs")
+	if err != nil {
+		t.Fatalf("ListPages failed: %v", err)
+	}
+
+	// The root is always kept; of the linked pages only the included one is followed
+	var urls []string
+	for _, page := range pages {
+		urls = append(urls, strings.TrimPrefix(page.URL, server.URL))
+	}
+	if strings.Join(urls, ",") != "/docs,/docs/guides/deploy" {
+		t.Errorf("Expected the root and the included guide, got %v", urls)
+	}
+
+	// Prefixes are URL paths
+	config = strings.Replace(config, "include: [/docs/guides]", "include: [docs/guides]", 1)
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := external.LoadServices(configPath); err == nil {
+		t.Error("Expected a relative path prefix to be rejected")
+	}
+}
+
+func TestLoadServices_MissingConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	// The server falls back to the built-in services only when the file does not exist
+	if _, err := external.LoadServices(filepath.Join(dir, "services.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected a not-exist error for a missing config, got %v", err)
+	}
+
+	brokenPath := filepath.Join(dir, "broken.json")
+	if err := os.WriteFile(brokenPath, []byte(`{"services": [{"id": "Bad ID"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := external.LoadServices(brokenPath); err == nil || errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected an invalid config to fail without falling back, got %v", err)
+	}
+}
+
+func TestServer_ServiceInfoFromCachedDocs(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/handbook":
+			fmt.Fprint(w, `<article><h1>Handbook</h1><p>Read this first.</p><pre><code>make ship
+
+make verify</code></pre>
+<p>The handbook explains how the team <a href="/handbook/deploy">deploys</a> and <a href="/handbook/alerts">monitors</a> its applications.</p></article>`)
+		case "/handbook/deploy":
+			fmt.Fprint(w, `<article><h1>Deploying</h1><h2>Installation</h2><p>Install the CLI.</p><h2>Zero Downtime Deploys</h2><p>Releases are swapped atomically.</p><h2>Rollbacks</h2><p>Redeploy the previous release.</p></article>`)
+		case "/handbook/alerts":
+			fmt.Fprint(w, `<article><h1>Alerts</h1><h2>Paging</h2><p>The on-call engineer is paged.</p><h2>rollbacks</h2><p>Failed deploys roll back.</p></article>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer site.Close()
+
+	configPath := filepath.Join(t.TempDir(), "services.yaml")
+	config := fmt.Sprintf(`services:
+  - id: handbook
+    name: Team Handbook
+    url: %s/handbook
+    description: How the team works.
+    features: [Guides]
+    crawl: {max_depth: 1, delay: 0s}
+`, site.URL)
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write services config: %v", err)
+	}
+	services, err := external.LoadServices(configPath)
+	if err != nil {
+		t.Fatalf("LoadServices failed: %v", err)
+	}
+
+	cacheDir := t.TempDir()
+	manager := external.NewExternalManager(cacheDir)
+	manager.SetServices(services)
+
+	srv := server.NewServer(docs.NewManager(t.TempDir(), "12.x"))
+	srv.RegisterExternalServiceTools(manager)
+	session := connectClient(t, srv)
+
+	// Without a cache the registry describes the service
+	ctx := context.Background()
+	if _, err := manager.Overview(ctx, "handbook"); err == nil || !strings.Contains(err.Error(), "not cached") {
+		t.Errorf("Expected the overview of an uncached service to fail, got %v", err)
+	}
+	var info server.ServiceInfo
+	result := callTool(t, session, "get_laravel_service_info", map[string]any{"service": "handbook"}, &info)
+	if result.IsError || info.Source != "registry" || info.Description != "How the team works." || strings.Join(info.Features, ",") != "Guides" {
+		t.Errorf("Expected the registry description, got %+v", info)
+	}
+	if !strings.Contains(resultText(result), "_Static description. Use update_external_laravel_docs with handbook") {
+		t.Errorf("Expected a hint to fetch the docs, got:\n%s", resultText(result))
+	}
+
+	if _, err := manager.UpdateService(ctx, "handbook", false); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+
+	info = server.ServiceInfo{}
+	result = callTool(t, session, "get_laravel_service_info", map[string]any{"service": "handbook"}, &info)
+	if result.IsError || info.Source != "docs" || info.Pages != 3 || info.CachedAt == "" {
+		t.Fatalf("Expected a description from the cached docs, got %+v", info)
+	}
+	// Short paragraphs and code are skipped, link targets dropped
+	if info.Description != "The handbook explains how the team deploys and monitors its applications." {
+		t.Errorf("Unexpected description %q", info.Description)
+	}
+	if strings.Join(info.Sections, ",") != "Deploying,Alerts" {
+		t.Errorf("Expected the pages below the root as sections, got %q", info.Sections)
+	}
+	// Features alternate between pages, without generic or repeated headings
+	if strings.Join(info.Features, ",") != "Zero Downtime Deploys,Paging,Rollbacks" {
+		t.Errorf("Unexpected features %q", info.Features)
+	}
+	if !strings.Contains(resultText(result), "_From 3 cached pages, updated") {
+		t.Errorf("Expected the cache age in the Markdown, got:\n%s", resultText(result))
+	}
+}
@@ -0,0 +1,150 @@
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/external"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/scheduler"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/updater"
+)
+
+// expireServiceCache backdates the cache metadata of an external service
+func expireServiceCache(t *testing.T, cacheDir, service string) {
+	t.Helper()
+
+	path := filepath.Join(cacheDir, service+"_metadata.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var metadata external.ServiceMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		t.Fatal(err)
+	}
+	metadata.CachedAt = time.Now().Add(-48 * time.Hour)
+	if data, err = json.Marshal(metadata); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScheduler_RefreshOnce(t *testing.T) {
+	sourceDir := t.TempDir()
+	writeDocs(t, sourceDir, "12.x", map[string]string{"routing.md": "# Routing"})
+
+	docsDir := t.TempDir()
+	upd := updater.NewGitHubUpdater(docsDir)
+	upd.SetSource(updater.NewFilesystemSource(sourceDir))
+	if _, err := upd.SyncDocs(context.Background(), "12.x", false, nil); err != nil {
+		t.Fatalf("SyncDocs failed: %v", err)
+	}
+
+	var changed []string
+	sched := scheduler.New(upd, nil, time.Hour)
+	sched.OnDocsChanged(func(version string) { changed = append(changed, version) })
+
+	// Nothing changed at the source
+	ctx := context.Background()
+	sched.RefreshOnce(ctx)
+	if len(changed) != 0 {
+		t.Errorf("Expected no callbacks, got %v", changed)
+	}
+
+	writeDocs(t, sourceDir, "12.x", map[string]string{"routing.md": "# Routing\n\nUpdated"})
+	sched.RefreshOnce(ctx)
+	if strings.Join(changed, ",") != "12.x" {
+		t.Errorf("Expected one callback for 12.x, got %v", changed)
+	}
+	if content, err := os.ReadFile(filepath.Join(docsDir, "12.x", "routing.md")); err != nil || string(content) != "# Routing\n\nUpdated" {
+		t.Errorf("Expected the updated routing.md, got %q (%v)", content, err)
+	}
+}
+
+func TestScheduler_RefreshExpiredServices(t *testing.T) {
+	var crawls atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/handbook" {
+			http.NotFound(w, r)
+			return
+		}
+		crawls.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<article><h1>Handbook</h1><p>Revision %d of the handbook.</p></article>", crawls.Load())
+	}))
+	defer site.Close()
+
+	configPath := filepath.Join(t.TempDir(), "services.yaml")
+	config := fmt.Sprintf("services:\n  - id: handbook\n    name: Team Handbook\n    url: %s/handbook\n    crawl: {max_depth: 0, delay: 0s}\n", site.URL)
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	services, err := external.LoadServices(configPath)
+	if err != nil {
+		t.Fatalf("LoadServices failed: %v", err)
+	}
+
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+	manager := external.NewExternalManager(cacheDir)
+	manager.SetServices(services)
+	if _, err := manager.UpdateService(ctx, "handbook", false); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+
+	sched := scheduler.New(updater.NewGitHubUpdater(t.TempDir()), manager, time.Hour)
+
+	// A fresh cache is left alone
+	sched.RefreshOnce(ctx)
+	if crawls.Load() != 1 {
+		t.Errorf("Expected no crawl of a fresh cache, got %d crawls", crawls.Load())
+	}
+
+	expireServiceCache(t, cacheDir, "handbook")
+	sched.RefreshOnce(ctx)
+	if crawls.Load() != 2 || len(manager.StaleServices()) != 0 {
+		t.Errorf("Expected the expired service to be crawled again, got %d crawls and stale %v", crawls.Load(), manager.StaleServices())
+	}
+	page, err := manager.ReadPage(ctx, "handbook", "handbook", "", false)
+	if err != nil || !strings.Contains(page.Content, "Revision 2") {
+		t.Errorf("Expected the refreshed page, got %+v (%v)", page, err)
+	}
+}
+
+func TestScheduler_RefreshStopsOnRateLimit(t *testing.T) {
+	// Every API request is rejected by a secondary rate limit an hour long
+	var requests atomic.Int32
+	docsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer docsServer.Close()
+
+	docsDir := t.TempDir()
+	writeDocs(t, docsDir, "11.x", map[string]string{"routing.md": "# Routing"})
+	writeDocs(t, docsDir, "12.x", map[string]string{"routing.md": "# Routing"})
+
+	upd := updater.NewGitHubUpdater(docsDir)
+	upd.SetSource(updater.NewGitHubEnterpriseSource(docsServer.URL, "laravel/docs"))
+
+	sched := scheduler.New(upd, nil, time.Hour)
+	sched.OnDocsChanged(func(version string) { t.Errorf("Unexpected change of %s", version) })
+	sched.RefreshOnce(context.Background())
+
+	// The second version would hit the same limit, so it is not requested
+	if requests.Load() != 1 {
+		t.Errorf("Expected the refresh to stop after the first rate limited request, got %d requests", requests.Load())
+	}
+}
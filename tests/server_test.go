@@ -0,0 +1,562 @@
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/docs"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/external"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/server"
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/updater"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// writeDocs writes documentation files for a version below docsPath
+func writeDocs(t *testing.T, docsPath, version string, files map[string]string) {
+	t.Helper()
+
+	versionDir := filepath.Join(docsPath, version)
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(versionDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// connectClient connects an in-memory MCP client to srv
+func connectClient(t *testing.T, srv *server.Server) *mcp.ClientSession {
+	t.Helper()
+	return connectClientWithOptions(t, srv, nil)
+}
+
+// connectClientWithOptions connects an in-memory MCP client with opts to srv
+func connectClientWithOptions(t *testing.T, srv *server.Server, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.GetMCPServer().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server Connect failed: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, opts)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect failed: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+
+	return session
+}
+
+// callTool calls a tool and decodes its structured content into output
+func callTool(t *testing.T, session *mcp.ClientSession, name string, args map[string]any, output any) *mcp.CallToolResult {
+	t.Helper()
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool(%s) failed: %v", name, err)
+	}
+	if output != nil && result.StructuredContent != nil {
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, output); err != nil {
+			t.Fatalf("Failed to decode structured content of %s: %v", name, err)
+		}
+	}
+	return result
+}
+
+// resultText joins the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var text strings.Builder
+	for _, content := range result.Content {
+		if tc, ok := content.(*mcp.TextContent); ok {
+			text.WriteString(tc.Text)
+		}
+	}
+	return text.String()
+}
+
+// stalledSource holds every sync at its first request until release is closed
+type stalledSource struct {
+	updater.DocSource
+	release chan struct{}
+}
+
+func (s *stalledSource) HeadRevision(ctx context.Context, version string) (string, error) {
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	return s.DocSource.HeadRevision(ctx, version)
+}
+
+func TestServer_StructuredContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeDocs(t, tmpDir, "12.x", map[string]string{
+		"routing.md": "# Routing\n\n## Basic Routing\n\nRoutes accept a URI and a closure.\n\n### Redirect Routes\n\nUse Route::redirect to redirect.",
+	})
+
+	srv := server.NewServer(docs.NewManager(tmpDir, "12.x"))
+	if err := srv.RegisterDocTools(); err != nil {
+		t.Fatal(err)
+	}
+	session := connectClient(t, srv)
+
+	var section server.SectionOutput
+	result := callTool(t, session, "read_laravel_doc_section", map[string]any{
+		"filename": "routing.md",
+		"section":  "Redirect Routes",
+	}, &section)
+	if result.IsError {
+		t.Fatalf("read_laravel_doc_section failed: %s", resultText(result))
+	}
+	if section.Version != "12.x" || section.Anchor != "redirect-routes" || section.Level != 3 {
+		t.Errorf("Unexpected structured section: %+v", section)
+	}
+	if len(section.Breadcrumb) != 2 || section.Breadcrumb[1] != "Basic Routing" {
+		t.Errorf("Expected the parent headings as breadcrumb, got %v", section.Breadcrumb)
+	}
+	// The Markdown is rendered from the same section
+	if text := resultText(result); !strings.Contains(text, "routing.md#redirect-routes") || !strings.Contains(text, section.Content) {
+		t.Errorf("Expected the Markdown to match the structured section:\n%s", text)
+	}
+
+	var search server.SearchOutput
+	result = callTool(t, session, "search_laravel_docs", map[string]any{
+		"query":            "redirect",
+		"version":          "12.x",
+		"include_external": false,
+	}, &search)
+	if result.IsError || len(search.Results) == 0 || search.Results[0].Anchor != "redirect-routes" {
+		t.Errorf("Expected redirect-routes as the top structured result, got %+v", search)
+	}
+
+	// Every tool advertises the schema of its structured content
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	for _, tool := range tools.Tools {
+		if tool.Name != "read_laravel_doc_section" {
+			continue
+		}
+		data, _ := json.Marshal(tool.OutputSchema)
+		var schema struct {
+			Type       string                     `json:"type"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			t.Fatalf("Failed to decode output schema: %v", err)
+		}
+		for _, property := range []string{"version", "filename", "anchor", "breadcrumb", "content"} {
+			if _, ok := schema.Properties[property]; !ok {
+				t.Errorf("Expected %q in the output schema, got %s", property, data)
+			}
+		}
+		return
+	}
+	t.Error("read_laravel_doc_section was not listed")
+}
+
+func TestServer_BootstrapBlocksOnlyTargetVersion(t *testing.T) {
+	sourceDir := t.TempDir()
+	writeDocs(t, sourceDir, "12.x", map[string]string{"routing.md": "# Routing\n\nRoutes accept a URI and a closure."})
+	source, err := updater.ParseDocSource(sourceDir, "", "")
+	if err != nil {
+		t.Fatalf("ParseDocSource failed: %v", err)
+	}
+
+	// Hold the sync at the docs source until the checks below are done
+	stalled := &stalledSource{DocSource: source, release: make(chan struct{})}
+	release := sync.OnceFunc(func() { close(stalled.release) })
+	defer release()
+
+	// An older version is on disk, the default version is not downloaded yet
+	tmpDir := t.TempDir()
+	writeDocs(t, tmpDir, "11.x", map[string]string{"routing.md": "# Routing\n\nRoutes accept a URI and a closure."})
+
+	upd := updater.NewGitHubUpdater(tmpDir)
+	upd.SetSource(stalled)
+
+	srv := server.NewServer(docs.NewManager(tmpDir, "12.x"))
+	srv.SetUpdater(upd)
+	if err := srv.RegisterDocTools(); err != nil {
+		t.Fatal(err)
+	}
+	session := connectClient(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv.BootstrapDocs(ctx, "12.x")
+
+	read := map[string]any{"filename": "routing.md"}
+	if text := resultText(callTool(t, session, "read_laravel_doc_content", read, nil)); !strings.Contains(text, "12.x documentation is syncing") {
+		t.Errorf("Expected reads of the default version to report the sync, got %q", text)
+	}
+
+	// Searches across versions and reads of other versions do not wait
+	var search server.SearchOutput
+	result := callTool(t, session, "search_laravel_docs", map[string]any{"query": "closure", "include_external": false}, &search)
+	if result.IsError || len(search.Results) == 0 || search.Results[0].Version != "11.x" {
+		t.Errorf("Expected the all-versions search to find 11.x, got %q", resultText(result))
+	}
+	read11 := map[string]any{"filename": "routing.md", "version": "11.x"}
+	if result := callTool(t, session, "read_laravel_doc_content", read11, nil); result.IsError || strings.Contains(resultText(result), "syncing") {
+		t.Errorf("Expected 11.x to be readable during the sync, got %q", resultText(result))
+	}
+
+	release()
+
+	// Once downloaded the default version is served normally
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var doc server.DocContentOutput
+		result := callTool(t, session, "read_laravel_doc_content", read, &doc)
+		if !result.IsError && doc.Version == "12.x" && strings.HasPrefix(doc.Content, "# Routing") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 12.x to be readable after the bootstrap, got %q", resultText(result))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestServer_UpdateDocsDefaultsToDefaultVersion(t *testing.T) {
+	sourceDir := t.TempDir()
+	writeDocs(t, sourceDir, "11.x", map[string]string{"routing.md": "# Routing"})
+
+	source, err := updater.ParseDocSource(sourceDir, "", "")
+	if err != nil {
+		t.Fatalf("ParseDocSource failed: %v", err)
+	}
+
+	tmpDir := t.TempDir()
+	upd := updater.NewGitHubUpdater(tmpDir)
+	upd.SetSource(source)
+
+	srv := server.NewServer(docs.NewManager(tmpDir, "11.x"))
+	srv.RegisterExternalTools(upd, external.NewWebScraper())
+	session := connectClient(t, srv)
+
+	var output server.DocsUpdateOutput
+	result := callTool(t, session, "update_laravel_docs", map[string]any{}, &output)
+	if result.IsError || output.Version != "11.x" {
+		t.Fatalf("Expected the default version to be updated, got %+v: %s", output, resultText(result))
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "11.x", "routing.md")); err != nil {
+		t.Errorf("Expected 11.x to be downloaded: %v", err)
+	}
+}
+
+func TestServer_UpdateProgressNotifications(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		files[fmt.Sprintf("page-%02d.md", i)] = fmt.Sprintf("# Page %d", i)
+	}
+	sourceDir := t.TempDir()
+	writeDocs(t, sourceDir, "12.x", files)
+
+	source, err := updater.ParseDocSource(sourceDir, "", "")
+	if err != nil {
+		t.Fatalf("ParseDocSource failed: %v", err)
+	}
+	tmpDir := t.TempDir()
+	upd := updater.NewGitHubUpdater(tmpDir)
+	upd.SetSource(source)
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<article><h1>%s</h1><p>How the team works.</p></article>", r.URL.Path)
+	}))
+	defer site.Close()
+
+	configPath := filepath.Join(t.TempDir(), "services.yaml")
+	config := fmt.Sprintf("services:\n  - id: handbook\n    name: Handbook\n    url: %[1]s/handbook\n    crawl: {max_depth: 0, delay: 0s}\n"+
+		"  - id: wiki\n    name: Wiki\n    url: %[1]s/wiki\n    crawl: {max_depth: 0, delay: 0s}\n", site.URL)
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	services, err := external.LoadServices(configPath)
+	if err != nil {
+		t.Fatalf("LoadServices failed: %v", err)
+	}
+	externalManager := external.NewExternalManager(t.TempDir())
+	externalManager.SetServices(services)
+
+	srv := server.NewServer(docs.NewManager(tmpDir, "12.x"))
+	srv.RegisterExternalTools(upd, external.NewWebScraper())
+	srv.RegisterExternalServiceTools(externalManager)
+
+	var mu sync.Mutex
+	notifications := make(map[any][]*mcp.ProgressNotificationParams)
+	session := connectClientWithOptions(t, srv, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			notifications[req.Params.ProgressToken] = append(notifications[req.Params.ProgressToken], req.Params)
+		},
+	})
+
+	tests := []struct {
+		tool  string
+		args  map[string]any
+		total int
+	}{
+		{"update_laravel_docs", map[string]any{"version_param": "12.x"}, len(files)},
+		{"update_external_laravel_docs", map[string]any{"services": []string{"handbook", "wiki"}}, 2},
+	}
+	for _, tc := range tests {
+		// SetProgressToken drops the token when Meta is nil, so it is set directly
+		params := &mcp.CallToolParams{Meta: mcp.Meta{"progressToken": tc.tool}, Name: tc.tool, Arguments: tc.args}
+		result, err := session.CallTool(context.Background(), params)
+		if err != nil || result.IsError {
+			t.Fatalf("CallTool(%s) failed: %v %s", tc.tool, err, resultText(result))
+		}
+
+		// Notifications are handled after the response may have arrived
+		var received []*mcp.ProgressNotificationParams
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			mu.Lock()
+			received = append([]*mcp.ProgressNotificationParams(nil), notifications[tc.tool]...)
+			mu.Unlock()
+			if n := len(received); n > 0 && received[n-1].Progress == float64(tc.total) || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if len(received) < 2 {
+			t.Fatalf("Expected several progress notifications for %s, got %d", tc.tool, len(received))
+		}
+		for i, n := range received {
+			if i > 0 && n.Progress < received[i-1].Progress {
+				t.Errorf("%s progress went backwards: %v after %v", tc.tool, n.Progress, received[i-1].Progress)
+			}
+			if n.Total != 0 && n.Total != float64(tc.total) {
+				t.Errorf("%s reported a total of %v, expected %d", tc.tool, n.Total, tc.total)
+			}
+		}
+		if last := received[len(received)-1]; last.Progress != float64(tc.total) || last.Total != float64(tc.total) {
+			t.Errorf("Expected %s to finish at %d of %d, got %v of %v", tc.tool, tc.total, tc.total, last.Progress, last.Total)
+		}
+	}
+}
+
+func TestServer_PlanUpgradePromptKeepsHighestImpact(t *testing.T) {
+	var guide strings.Builder
+	guide.WriteString("# Upgrade Guide\n\n## Upgrading To 12.0 From 11.x\n\n### Framework\n")
+	for i := 0; i < 30; i++ {
+		impact := "Low"
+		if i%6 == 0 {
+			impact = "High"
+		}
+		guide.WriteString(fmt.Sprintf("\n#### Change %02d\n\n**Likelihood Of Impact: %s**\n\nDetails of change %02d.\n", i, impact, i))
+	}
+
+	tmpDir := t.TempDir()
+	writeDocs(t, tmpDir, "12.x", map[string]string{"upgrade.md": guide.String()})
+
+	srv := server.NewServer(docs.NewManager(tmpDir, "12.x"))
+	srv.RegisterPrompts(nil)
+	session := connectClient(t, srv)
+
+	prompt, err := session.GetPrompt(context.Background(), &mcp.GetPromptParams{
+		Name:      "plan_laravel_upgrade",
+		Arguments: map[string]string{"from_version": "11.x", "to_version": "12.x"},
+	})
+	if err != nil {
+		t.Fatalf("GetPrompt failed: %v", err)
+	}
+
+	instructions, ok := prompt.Messages[0].Content.(*mcp.TextContent)
+	if !ok || !strings.Contains(instructions.Text, "Only the 25 highest impact of 30 entries are attached") {
+		t.Errorf("Expected the truncation note in the instructions, got %+v", prompt.Messages[0].Content)
+	}
+
+	embedded := make(map[string]string)
+	for _, message := range prompt.Messages[1:] {
+		if resource, ok := message.Content.(*mcp.EmbeddedResource); ok {
+			embedded[resource.Resource.URI] = resource.Resource.Text
+		}
+	}
+	if len(embedded) != 25 {
+		t.Fatalf("Expected 25 embedded entries, got %d", len(embedded))
+	}
+	for i := 0; i < 30; i += 6 {
+		uri := fmt.Sprintf("laravel-docs://12.x/upgrade.md#change-%02d", i)
+		if text, ok := embedded[uri]; !ok || !strings.Contains(text, "Likelihood of impact: high") {
+			t.Errorf("Expected high impact entry %s to be embedded, got %q", uri, text)
+		}
+	}
+	// The lowest ranked entries beyond the limit are left out
+	if _, ok := embedded["laravel-docs://12.x/upgrade.md#change-29"]; ok {
+		t.Error("Expected the last low impact entry to be left out")
+	}
+}
+
+func TestServer_DocResources(t *testing.T) {
+	files := map[string]string{
+		"routing.md": "# Routing\n\n## Basic Routing\n\nRoutes accept a URI and a closure.\n\n### Redirect Routes\n\nUse Route::redirect to redirect.",
+	}
+	for i := 0; i < 120; i++ {
+		files[fmt.Sprintf("page-%03d.md", i)] = fmt.Sprintf("# Page %d", i)
+	}
+	tmpDir := t.TempDir()
+	writeDocs(t, tmpDir, "12.x", files)
+
+	ctx := context.Background()
+	srv := server.NewServer(docs.NewManager(tmpDir, "12.x"))
+	if total := srv.RegisterDocResources(ctx); total != 121 {
+		t.Fatalf("Expected 121 resources, got %d", total)
+	}
+	session := connectClient(t, srv)
+
+	// Listing is paginated
+	first, err := session.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	if len(first.Resources) != 100 || first.NextCursor == "" {
+		t.Fatalf("Expected a first page of 100 resources with a cursor, got %d (%q)", len(first.Resources), first.NextCursor)
+	}
+	second, err := session.ListResources(ctx, &mcp.ListResourcesParams{Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	if len(second.Resources) != 21 || second.NextCursor != "" {
+		t.Errorf("Expected a last page of 21 resources, got %d (%q)", len(second.Resources), second.NextCursor)
+	}
+	seen := make(map[string]bool)
+	for _, resource := range append(first.Resources, second.Resources...) {
+		seen[resource.URI] = true
+	}
+	if len(seen) != 121 || !seen["laravel-docs://12.x/routing.md"] {
+		t.Errorf("Expected every file listed once, got %d distinct URIs", len(seen))
+	}
+
+	templates, err := session.ListResourceTemplates(ctx, nil)
+	if err != nil || len(templates.ResourceTemplates) != 2 {
+		t.Fatalf("Expected the file and section templates, got %v (%v)", templates, err)
+	}
+
+	// A file added after registration is served through the file template
+	writeDocs(t, tmpDir, "12.x", map[string]string{"queues.md": "# Queues\n\nJobs run in the background."})
+
+	for uri, want := range map[string]string{
+		"laravel-docs://12.x/routing.md":                 files["routing.md"],
+		"laravel-docs://12.x/routing.md#basic-routing":   "## Basic Routing\n\nRoutes accept a URI and a closure.\n\n### Redirect Routes\n\nUse Route::redirect to redirect.",
+		"laravel-docs://12.x/routing.md#redirect-routes": "### Redirect Routes\n\nUse Route::redirect to redirect.",
+		"laravel-docs://12.x/queues.md":                  "# Queues\n\nJobs run in the background.",
+	} {
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			t.Errorf("ReadResource(%s) failed: %v", uri, err)
+			continue
+		}
+		if len(result.Contents) != 1 || result.Contents[0].URI != uri || result.Contents[0].MIMEType != "text/markdown" || result.Contents[0].Text != want {
+			t.Errorf("Unexpected contents of %s: %+v", uri, result.Contents)
+		}
+	}
+
+	for _, uri := range []string{"laravel-docs://12.x/missing.md", "laravel-docs://12.x/routing.md#missing", "laravel-docs://12.x/nested/routing.md"} {
+		if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri}); err == nil {
+			t.Errorf("Expected reading %s to fail", uri)
+		}
+	}
+
+	// Refreshing a version registers its current files
+	if err := os.Remove(filepath.Join(tmpDir, "12.x", "page-000.md")); err != nil {
+		t.Fatal(err)
+	}
+	if total := srv.RefreshDocResources(ctx, "12.x"); total != 121 {
+		t.Errorf("Expected 121 resources after the refresh, got %d", total)
+	}
+}
+
+func TestServer_ServeHTTPTransports(t *testing.T) {
+	srv := server.NewServer(docs.NewManager(t.TempDir(), "12.x"))
+	if err := srv.RegisterDocTools(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := srv.Serve(context.Background(), "websocket", "127.0.0.1:0"); err == nil || !strings.Contains(err.Error(), "unknown transport") {
+		t.Errorf("Expected an unknown transport error, got %v", err)
+	}
+
+	for _, transport := range []string{server.TransportHTTP, server.TransportSSE} {
+		t.Run(transport, func(t *testing.T) {
+			// Reserve a free port for the server
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			addr := listener.Addr().String()
+			listener.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			served := make(chan error, 1)
+			go func() { served <- srv.Serve(ctx, transport, addr) }()
+
+			var clientTransport mcp.Transport = &mcp.StreamableClientTransport{Endpoint: "http://" + addr}
+			if transport == server.TransportSSE {
+				clientTransport = &mcp.SSEClientTransport{Endpoint: "http://" + addr}
+			}
+			client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+
+			var session *mcp.ClientSession
+			for deadline := time.Now().Add(5 * time.Second); ; {
+				if session, err = client.Connect(ctx, clientTransport, nil); err == nil {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("Failed to connect over %s: %v", transport, err)
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			tools, err := session.ListTools(ctx, nil)
+			if err != nil || len(tools.Tools) == 0 {
+				t.Errorf("Expected tools over %s, got %v (%v)", transport, tools, err)
+			}
+			session.Close()
+
+			cancel()
+			select {
+			case err := <-served:
+				if err != nil {
+					t.Errorf("Expected a clean shutdown, got %v", err)
+				}
+			case <-time.After(15 * time.Second):
+				t.Fatal("Serve did not return after ctx was cancelled")
+			}
+
+			if conn, err := net.Dial("tcp", addr); err == nil {
+				conn.Close()
+				t.Errorf("Expected %s to stop listening", addr)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package docs
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/izzamoe/laravel-mcp-companion-go/internal/updater"
+)
+
+func TestFilesystemSource_Sync(t *testing.T) {
+	sourceDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(sourceDir, "12.x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "12.x", "routing.md"), []byte("# Routing"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := updater.ParseDocSource(sourceDir, "", "")
+	if err != nil {
+		t.Fatalf("ParseDocSource failed: %v", err)
+	}
+
+	upd := updater.NewGitHubUpdater(t.TempDir())
+	upd.SetSource(source)
+
+	if _, err := upd.SyncDocs(context.Background(), "12.x", false, nil); err != nil {
+		t.Fatalf("SyncDocs failed: %v", err)
+	}
+
+	report, err := upd.SyncDocs(context.Background(), "12.x", false, nil)
+	if err != nil {
+		t.Fatalf("SyncDocs failed: %v", err)
+	}
+	if !report.UpToDate {
+		t.Errorf("Expected an unchanged directory to be up to date, got %+v", report)
+	}
+}
+
+func TestGitHubUpdater_ImportArchiveOffline(t *testing.T) {
+	// Any request to the docs source means the import was not offline
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "offline", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	archivePath := filepath.Join(t.TempDir(), "docs-13.x.zip")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("docs-13.x/routing.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprint(w, "# Routing")
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	tmpDir := t.TempDir()
+	upd := updater.NewGitHubUpdater(tmpDir)
+	upd.SetSource(updater.NewGitHubEnterpriseSource(server.URL, "laravel/docs"))
+
+	// 13.x is neither built in nor on disk yet
+	report, err := upd.ImportArchive(context.Background(), archivePath, "13.x", false)
+	if err != nil {
+		t.Fatalf("ImportArchive failed: %v", err)
+	}
+	if len(report.Added) != 1 || requests != 0 {
+		t.Errorf("Expected 1 added file without network requests, got %+v with %d requests", report, requests)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "13.x", "routing.md")); err != nil {
+		t.Errorf("Expected imported routing.md: %v", err)
+	}
+
+	if _, err := upd.ImportArchive(context.Background(), archivePath, "../13.x", false); err == nil {
+		t.Error("Expected an invalid version to be rejected")
+	}
+}
+
+func TestGitHubUpdater_ImportGitCheckoutOffline(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repoPath := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	git("init", "-q", "-b", "13.x")
+	for name, content := range map[string]string{"routing.md": "# Routing", "mail.md": "# Mail", "readme.txt": "not docs"} {
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "docs")
+	commit := git("rev-parse", "HEAD")
+
+	tmpDir := t.TempDir()
+	upd := updater.NewGitHubUpdater(tmpDir)
+
+	// The ref defaults to the version branch of the checkout
+	report, err := upd.ImportGitCheckout(context.Background(), repoPath, "13.x", "", false)
+	if err != nil {
+		t.Fatalf("ImportGitCheckout failed: %v", err)
+	}
+	if len(report.Added) != 2 || report.Commit != commit {
+		t.Errorf("Expected 2 added files at %s, got %+v", commit, report)
+	}
+	content, err := os.ReadFile(filepath.Join(tmpDir, "13.x", "mail.md"))
+	if err != nil || string(content) != "# Mail" {
+		t.Errorf("Expected imported mail.md, got %q (%v)", content, err)
+	}
+
+	report, err = upd.ImportGitCheckout(context.Background(), repoPath, "13.x", "", false)
+	if err != nil {
+		t.Fatalf("ImportGitCheckout failed: %v", err)
+	}
+	if !report.UpToDate {
+		t.Errorf("Expected the same commit to be up to date, got %+v", report)
+	}
+
+	if _, err := upd.ImportGitCheckout(context.Background(), repoPath, "13.x", "missing-branch", false); err == nil {
+		t.Error("Expected an unknown ref to be rejected")
+	}
+}